@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	maxAssetSize = 5 << 20          // Largest asset downloaded for offline use
+	assetTimeout = 15 * time.Second // Per asset, so a slow host can't stall unpacking
+)
+
+// assetHosts lists the hosts images and other assets may be fetched from
+var assetHosts = map[string]bool{
+	"devdocs.io":                        true,
+	"documents.devdocs.io":              true,
+	"developer.mozilla.org":             true,
+	"mdn.mozillademos.org":              true,
+	"upload.wikimedia.org":              true,
+	"raw.githubusercontent.com":         true,
+	"user-images.githubusercontent.com": true,
+}
+
+// assetFetcher downloads assets referenced by a docset's pages and
+// remembers what it already fetched so pages share local copies
+type assetFetcher struct {
+	cache   *Cache
+	slug    string
+	client  *http.Client
+	fetched map[string]string // remote URL -> local file path, "" if it failed
+}
+
+func newAssetFetcher(cache *Cache, slug string) *assetFetcher {
+	return &assetFetcher{
+		cache:   cache,
+		slug:    slug,
+		client:  &http.Client{Timeout: assetTimeout},
+		fetched: make(map[string]string),
+	}
+}
+
+// localURL returns the path of the local copy of rawURL relative to pageDir,
+// or rawURL itself if the asset can't be fetched
+func (f *assetFetcher) localURL(pageDir, rawURL string) string {
+	remote := rawURL
+	if strings.HasPrefix(remote, "//") {
+		remote = "https:" + remote
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !assetHosts[u.Hostname()] {
+		return rawURL
+	}
+
+	local, ok := f.fetched[remote]
+	if !ok {
+		local, err = f.fetch(u)
+		if err != nil {
+			local = ""
+		}
+		f.fetched[remote] = local
+	}
+	if local == "" {
+		return rawURL
+	}
+
+	rel, err := filepath.Rel(pageDir, local)
+	if err != nil {
+		return rawURL
+	}
+	return filepath.ToSlash(rel)
+}
+
+// fetch downloads an asset into the docset's assets directory
+func (f *assetFetcher) fetch(u *url.URL) (string, error) {
+	sum := sha1.Sum([]byte(u.String()))
+	name := hex.EncodeToString(sum[:8]) + path.Ext(u.Path)
+	local := filepath.Join(f.cache.GetAssetsDir(f.slug), name)
+
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	}
+
+	resp, err := f.client.Get(u.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s for %s", resp.Status, u)
+	}
+	if resp.ContentLength > maxAssetSize {
+		return "", fmt.Errorf("asset %s is too large", u)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxAssetSize {
+		return "", fmt.Errorf("asset %s is too large", u)
+	}
+
+	if err := os.WriteFile(local, data, 0644); err != nil {
+		return "", err
+	}
+	return local, nil
+}
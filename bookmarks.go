@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/v2/list"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+type Bookmark struct {
+	Docset  string `json:"docset"`
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Tag     string `json:"tag,omitempty"`
+	Missing bool   `json:"missing,omitempty"` // Entry disappeared in a docset update
+}
+
+func (b Bookmark) Entry() DocumentEntry {
+	return DocumentEntry{Name: b.Name, Path: b.Path, Type: b.Type}
+}
+
+func (c *Cache) bookmarksPath() string {
+	return filepath.Join(c.BaseDir, "bookmarks.json")
+}
+
+func (c *Cache) GetBookmarks() ([]Bookmark, error) {
+	data, err := os.ReadFile(c.bookmarksPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []Bookmark
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, fmt.Errorf("failed to parse bookmarks: %w", err)
+	}
+	return bookmarks, nil
+}
+
+func (c *Cache) SaveBookmarks(bookmarks []Bookmark) error {
+	if err := os.MkdirAll(c.BaseDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.bookmarksPath(), data, 0644)
+}
+
+// IsBookmarked reports whether an entry of a docset is bookmarked
+func (c *Cache) IsBookmarked(docset string, entry DocumentEntry) bool {
+	bookmarks, err := c.GetBookmarks()
+	if err != nil {
+		return false
+	}
+	for _, b := range bookmarks {
+		if b.Docset == docset && b.Path == entry.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBookmark bookmarks an entry, updating the tag if it is already bookmarked
+func (c *Cache) AddBookmark(docset string, entry DocumentEntry, tag string) error {
+	bookmarks, err := c.GetBookmarks()
+	if err != nil {
+		return err
+	}
+
+	for i, b := range bookmarks {
+		if b.Docset == docset && b.Path == entry.Path {
+			bookmarks[i].Tag = tag
+			return c.SaveBookmarks(bookmarks)
+		}
+	}
+
+	bookmarks = append(bookmarks, Bookmark{
+		Docset: docset,
+		Path:   entry.Path,
+		Name:   entry.Name,
+		Type:   entry.Type,
+		Tag:    tag,
+	})
+	return c.SaveBookmarks(bookmarks)
+}
+
+// RemoveBookmark removes the bookmark of an entry, matched by path or name
+func (c *Cache) RemoveBookmark(docset string, entry string) (bool, error) {
+	bookmarks, err := c.GetBookmarks()
+	if err != nil {
+		return false, err
+	}
+
+	kept := bookmarks[:0]
+	removed := false
+	for _, b := range bookmarks {
+		if b.Docset == docset && (b.Path == entry || b.Name == entry) {
+			removed = true
+			continue
+		}
+		kept = append(kept, b)
+	}
+	if !removed {
+		return false, nil
+	}
+	return true, c.SaveBookmarks(kept)
+}
+
+// ToggleBookmark adds or removes the bookmark of an entry and reports
+// whether the entry is bookmarked afterwards
+func (c *Cache) ToggleBookmark(docset string, entry DocumentEntry) (bool, error) {
+	if c.IsBookmarked(docset, entry) {
+		_, err := c.RemoveBookmark(docset, entry.Path)
+		return false, err
+	}
+	return true, c.AddBookmark(docset, entry, "")
+}
+
+// ResolveBookmarks re-resolves the bookmarks of a docset against its new
+// entries. Entries are matched by path first and by name second, and
+// bookmarks without a match are flagged as missing.
+func (c *Cache) ResolveBookmarks(docset string, entries []DocumentEntry) error {
+	bookmarks, err := c.GetBookmarks()
+	if err != nil || len(bookmarks) == 0 {
+		return err
+	}
+
+	byPath := make(map[string]DocumentEntry, len(entries))
+	byName := make(map[string]DocumentEntry, len(entries))
+	for _, entry := range entries {
+		byPath[entry.Path] = entry
+		if _, ok := byName[entry.Name]; !ok {
+			byName[entry.Name] = entry
+		}
+	}
+
+	for i, b := range bookmarks {
+		if b.Docset != docset {
+			continue
+		}
+
+		entry, ok := byPath[b.Path]
+		if !ok {
+			entry, ok = byName[b.Name]
+		}
+		if !ok {
+			bookmarks[i].Missing = true
+			continue
+		}
+
+		bookmarks[i].Path = entry.Path
+		bookmarks[i].Name = entry.Name
+		bookmarks[i].Type = entry.Type
+		bookmarks[i].Missing = false
+	}
+
+	return c.SaveBookmarks(bookmarks)
+}
+
+type bookmarkItem Bookmark
+
+func (i bookmarkItem) FilterValue() string { return i.Name + " " + i.Tag }
+
+type bookmarkDelegate struct{}
+
+func (d bookmarkDelegate) Height() int                             { return 1 }
+func (d bookmarkDelegate) Spacing() int                            { return 0 }
+func (d bookmarkDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d bookmarkDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(bookmarkItem)
+	if !ok {
+		return
+	}
+
+	str := fmt.Sprintf("%s: %s", i.Docset, i.Name)
+	if i.Tag != "" {
+		str += fmt.Sprintf(" [%s]", i.Tag)
+	}
+	if i.Missing {
+		str += " (missing)"
+	}
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = selectedItemStyle.Render
+	}
+
+	fmt.Fprint(w, fn(str))
+}
+
+type BookmarkModel struct {
+	list  list.Model
+	cache *Cache
+	err   error
+}
+
+func NewBookmarkModel(cache *Cache, bookmarks []Bookmark) BookmarkModel {
+	items := make([]list.Item, len(bookmarks))
+	for i, b := range bookmarks {
+		items[i] = bookmarkItem(b)
+	}
+
+	l := list.New(items, bookmarkDelegate{}, 80, 30)
+	l.Title = "Bookmarks"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = titleStyle
+	l.Styles.PaginationStyle = paginationStyle
+	l.Styles.HelpStyle = helpStyle
+
+	return BookmarkModel{
+		list:  l,
+		cache: cache,
+	}
+}
+
+func (m BookmarkModel) Init() (tea.Model, tea.Cmd) {
+	return m, nil
+}
+
+func (m BookmarkModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height-4)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "o", "enter":
+			if m.list.SettingFilter() {
+				break
+			}
+			if i, ok := m.list.SelectedItem().(bookmarkItem); ok {
+				if err := openEntry(m.cache, i.Docset, Bookmark(i).Entry()); err != nil {
+					m.err = err
+					return m, nil
+				}
+				return m, tea.Quit
+			}
+		case "x":
+			if m.list.SettingFilter() {
+				break
+			}
+			if i, ok := m.list.SelectedItem().(bookmarkItem); ok {
+				if _, err := m.cache.RemoveBookmark(i.Docset, i.Path); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.list.RemoveItem(m.list.Index())
+				return m, nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m BookmarkModel) View() string {
+	view := "\n" + m.list.View()
+	if m.err != nil {
+		view += "\nError: " + m.err.Error()
+	}
+	return view
+}
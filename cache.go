@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
 const DefaultDevDocsDir = ".local/share/devdocs"
 
 type DocMeta struct {
+	Slug    string `json:"slug,omitempty"`
 	Release string `json:"release"`
 	Version string `json:"version"`
 	Mtime   int64  `json:"mtime"`
@@ -19,6 +19,7 @@ type DocMeta struct {
 
 type Cache struct {
 	BaseDir string
+	store   *indexStore // In-memory indexes when running as the daemon
 }
 
 func newCache() *Cache {
@@ -58,6 +59,55 @@ func (c *Cache) GetMeta(slug string) (DocMeta, error) {
 	return meta, nil
 }
 
+// InstalledSlugs maps devdocs slugs of installed docsets, with and without
+// their version suffix, to the directory they are installed in. A slug
+// without version maps to the family directory, or to the newest version
+// installed next to it when there is no family directory.
+func (c *Cache) InstalledSlugs() map[string]string {
+	slugs := make(map[string]string)
+
+	entries, err := os.ReadDir(c.BaseDir)
+	if err != nil {
+		return slugs
+	}
+
+	// A family directory is named after the docset, a version installed
+	// next to it after its devdocs slug
+	type version struct{ dir, version string }
+	families := make(map[string]string)
+	newest := make(map[string]version)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := entry.Name()
+		slugs[dir] = dir
+
+		meta, err := c.GetMeta(dir)
+		if err != nil || meta.Slug == "" {
+			continue
+		}
+		slugs[meta.Slug] = dir
+
+		base, v, ok := strings.Cut(meta.Slug, "~")
+		switch {
+		case !ok:
+		case dir != meta.Slug:
+			families[base] = dir
+		case newest[base].dir == "" || CompareVersions(v, newest[base].version) > 0:
+			newest[base] = version{dir, v}
+		}
+	}
+
+	for base, v := range newest {
+		slugs[base] = v.dir
+	}
+	for base, dir := range families {
+		slugs[base] = dir
+	}
+	return slugs
+}
+
 func (c *Cache) GetIndex(slug string) ([]byte, error) {
 	path := filepath.Join(c.BaseDir, slug, "index.json")
 	return os.ReadFile(path)
@@ -103,7 +153,15 @@ func (c *Cache) EnsureHTMLDir(slug string) error {
 	return os.MkdirAll(c.GetHTMLDir(slug), 0755)
 }
 
-func (c *Cache) SaveHTML(slug string, path string, content string) error {
+func (c *Cache) GetAssetsDir(slug string) string {
+	return filepath.Join(c.BaseDir, slug, "assets")
+}
+
+func (c *Cache) EnsureAssetsDir(slug string) error {
+	return os.MkdirAll(c.GetAssetsDir(slug), 0755)
+}
+
+func (c *Cache) SaveHTML(slug string, path string, content string, links *docsetLinks) error {
 	// Get the file path, stripping any fragment
 	htmlPath, _ := c.GetHTMLPath(slug, path)
 
@@ -112,133 +170,11 @@ func (c *Cache) SaveHTML(slug string, path string, content string) error {
 		return fmt.Errorf("failed to create HTML directory: %w", err)
 	}
 
-
-	// Fix relative links in content - add the current directory info for relative path resolution
-	currentDir := filepath.Dir(path)
-	fmt.Println(htmlPath, path, currentDir)
-	fixedContent := c.fixRelativeLinksWithContext(content, currentDir)
-
-	return os.WriteFile(htmlPath, []byte(fixedContent), 0644)
-}
-
-// calculateRelativePath computes a relative path from source to target directory
-func calculateRelativePath(sourceDirParts, targetDirParts []string) string {
-	// Find common prefix length
-	commonLength := 0
-	for i := 0; i < len(sourceDirParts) && i < len(targetDirParts); i++ {
-		if sourceDirParts[i] != targetDirParts[i] {
-			break
-		}
-		commonLength++
-	}
-	
-	// Build relative path
-	var result strings.Builder
-	
-	// Add "../" for each directory level we need to go up
-	for i := 0; i < len(sourceDirParts)-commonLength; i++ {
-		if i > 0 {
-			result.WriteString("/")
-		}
-		result.WriteString("..")
-	}
-	
-	// Add path to target
-	for i := commonLength; i < len(targetDirParts); i++ {
-		if result.Len() > 0 {
-			result.WriteString("/")
-		}
-		result.WriteString(targetDirParts[i])
-	}
-	
-	// If empty, we're referring to the same directory
-	if result.Len() == 0 {
-		return "."
+	// Point links and images at the unpacked files
+	fixedContent, err := c.rewriteLinks(slug, path, content, links)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite links: %w", err)
 	}
-	
-	return result.String()
-}
-
-// fixRelativeLinksWithContext adds .html extension to relative links in HTML content
-// with awareness of the current document's directory
-func (c *Cache) fixRelativeLinksWithContext(content string, currentDir string) string {
-	re := regexp.MustCompile(`href="([^"]*)"`)
-	return re.ReplaceAllStringFunc(content, func(match string) string {
-		// Extract the URL from href="url"
-		url := match[6 : len(match)-1]
-
-		// Skip if it's an absolute URL or already has .html extension
-		if strings.Contains(url, "://") || // Has protocol (http://, https://, etc)
-			strings.HasPrefix(url, "//") || // Protocol-relative URL
-			strings.HasPrefix(url, "mailto:") || // Email link
-			strings.HasSuffix(url, ".html") { // Already has .html
-			return match
-		}
 
-		// Handle empty URLs or just fragments
-		if url == "" || url == "." || url == "./" {
-			return `href="index.html"`
-		}
-		
-		// Handle URLs that are just fragments
-		if strings.HasPrefix(url, "#") {
-			return fmt.Sprintf(`href="index.html%s"`, url)
-		}
-
-		// Handle absolute paths within the docset
-		isAbsolutePath := strings.HasPrefix(url, "/")
-		if isAbsolutePath {
-			// Remove the leading slash for processing
-			url = strings.TrimPrefix(url, "/")
-		}
-
-		// Remove any trailing slash
-		url = strings.TrimSuffix(url, "/")
-
-		// Split URL and fragment identifier
-		urlParts := strings.SplitN(url, "#", 2)
-		baseUrl := urlParts[0]
-		
-		// If baseUrl is empty or just ".", use index.html
-		if baseUrl == "" || baseUrl == "." {
-			baseUrl = "index"
-		}
-		
-		// Handle dotted paths (convert dots to directory separators)
-		if strings.Contains(baseUrl, ".") && !strings.Contains(baseUrl, "/") {
-			// This looks like a dotted path (like "language.types.array")
-			// Convert dots to slashes
-			targetPath := strings.ReplaceAll(baseUrl, ".", "/")
-			
-			// If we have a current directory context, create a proper relative path
-			if currentDir != "" && currentDir != "." {
-				// Split the paths into components
-				targetParts := strings.Split(targetPath, "/")
-				currentParts := strings.Split(currentDir, "/")
-				fmt.Println(currentParts, targetParts)
-				
-				// Calculate the relative path (how many "../" we need)
-				baseUrl = calculateRelativePath(currentParts, targetParts)
-			} else {
-				// No current directory, just use the slash path
-				baseUrl = targetPath
-			}
-		} else if !isAbsolutePath && !strings.Contains(url, "..") && currentDir != "" && currentDir != "." {
-			// For simple relative paths that aren't using dot notation and aren't already absolute
-			if !strings.Contains(baseUrl, "/") {
-				// Simple relative path in same directory
-				baseUrl = filepath.Join(currentDir, baseUrl)
-			}
-		}
-		
-		// Add .html extension to the base URL
-		if len(urlParts) > 1 {
-			// Reassemble with fragment
-			// Don't use the leading slash - causes file:// URL issues
-			return fmt.Sprintf(`href="%s.html#%s"`, baseUrl, urlParts[1])
-		}
-		
-		// No fragment
-		return fmt.Sprintf(`href="%s.html"`, baseUrl)
-	})
+	return os.WriteFile(htmlPath, []byte(fixedContent), 0644)
 }
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestInstalledSlugs(t *testing.T) {
+	cache := &Cache{BaseDir: t.TempDir()}
+	for dir, slug := range map[string]string{
+		"python":       "python~3.12",
+		"python~3.10":  "python~3.10",
+		"node.js":      "node",
+		"c++":          "cpp",
+		"ruby~3.2":     "ruby~3.2",
+		"ruby~3.10":    "ruby~3.10",
+		"half-fetched": "",
+	} {
+		if err := cache.EnsureDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		if slug != "" {
+			if err := cache.SaveMeta(dir, DocMeta{Slug: slug}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	slugs := cache.InstalledSlugs()
+	for slug, want := range map[string]string{
+		"python":       "python", // The family directory, not the version next to it
+		"python~3.12":  "python",
+		"python~3.10":  "python~3.10",
+		"node":         "node.js",
+		"node.js":      "node.js",
+		"cpp":          "c++",
+		"ruby":         "ruby~3.10", // No family directory, the newest version
+		"ruby~3.2":     "ruby~3.2",
+		"half-fetched": "half-fetched",
+	} {
+		if got := slugs[slug]; got != want {
+			t.Errorf("InstalledSlugs()[%q] = %q, want %q", slug, got, want)
+		}
+	}
+}
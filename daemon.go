@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	daemonPollInterval = 2 * time.Second // How often the daemon looks for changed docsets
+	daemonDialTimeout  = 100 * time.Millisecond
+	daemonTimeout      = 30 * time.Second // Per request
+)
+
+// daemonSocket is the Unix socket the daemon listens on
+func (c *Cache) daemonSocket() string {
+	return filepath.Join(c.BaseDir, "ddc.sock")
+}
+
+// storedDocset is the in-memory state of an installed docset
+type storedDocset struct {
+	stamp   time.Time // Latest modification of its index, sections and db.json
+	entries []DocumentEntry
+	offsets map[string][2]int64 // Byte ranges of documents in db.json, read on first use
+}
+
+// indexStore keeps the indexes of installed docsets and the offsets of their
+// documents in db.json in memory, for the daemon
+type indexStore struct {
+	cache   *Cache
+	mu      sync.RWMutex
+	docsets map[string]*storedDocset
+}
+
+func newIndexStore(cache *Cache) *indexStore {
+	return &indexStore{cache: cache, docsets: make(map[string]*storedDocset)}
+}
+
+// docsetStamp returns the latest modification time of the files of a docset
+// the store reads
+func (s *indexStore) docsetStamp(slug string) time.Time {
+	var stamp time.Time
+	for _, name := range []string{"index.json", "sections.json", "db.json"} {
+		info, err := os.Stat(filepath.Join(s.cache.GetDocPath(slug), name))
+		if err == nil && info.ModTime().After(stamp) {
+			stamp = info.ModTime()
+		}
+	}
+	return stamp
+}
+
+// load reads a docset into the store
+func (s *indexStore) load(slug string) (*storedDocset, error) {
+	stamp := s.docsetStamp(slug)
+	entries, err := readEntries(s.cache, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &storedDocset{stamp: stamp, entries: entries}
+	s.mu.Lock()
+	s.docsets[slug] = d
+	s.mu.Unlock()
+	return d, nil
+}
+
+func (s *indexStore) docset(slug string) (*storedDocset, error) {
+	s.mu.RLock()
+	d, ok := s.docsets[slug]
+	s.mu.RUnlock()
+	if ok {
+		return d, nil
+	}
+	return s.load(slug)
+}
+
+func (s *indexStore) entries(slug string) ([]DocumentEntry, error) {
+	d, err := s.docset(slug)
+	if err != nil {
+		return nil, err
+	}
+	return d.entries, nil
+}
+
+// document reads a single document from db.json at its stored offsets
+func (s *indexStore) document(slug, path string) (string, error) {
+	d, err := s.docset(slug)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.RLock()
+	offsets := d.offsets
+	s.mu.RUnlock()
+	if offsets == nil {
+		if offsets, err = documentOffsets(s.cache, slug); err != nil {
+			return "", err
+		}
+		s.mu.Lock()
+		d.offsets = offsets
+		s.mu.Unlock()
+	}
+
+	r, ok := offsets[path]
+	if !ok {
+		return "", fmt.Errorf("document not found: %s", path)
+	}
+
+	f, err := os.Open(filepath.Join(s.cache.GetDocPath(slug), "db.json"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read db.json: %w", err)
+	}
+	defer f.Close()
+
+	raw := make([]byte, r[1]-r[0])
+	if _, err := f.ReadAt(raw, r[0]); err != nil {
+		return "", fmt.Errorf("failed to read db.json: %w", err)
+	}
+
+	var content string
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return "", fmt.Errorf("failed to parse db.json: %w", err)
+	}
+	return content, nil
+}
+
+// documentOffsets maps the paths of the documents in db.json to the byte
+// ranges of their JSON strings
+func documentOffsets(cache *Cache, slug string) (map[string][2]int64, error) {
+	data, err := cache.GetDB(slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read db.json: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("failed to parse db.json: %w", err)
+	}
+
+	offsets := make(map[string][2]int64)
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse db.json: %w", err)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse db.json: %w", err)
+		}
+
+		// The value ends at the decoder offset, the raw message has its length
+		end := dec.InputOffset()
+		path, _ := key.(string)
+		offsets[path] = [2]int64{end - int64(len(raw)), end}
+	}
+	return offsets, nil
+}
+
+// refresh reloads docsets that changed since they were stored, drops removed
+// ones and loads newly installed ones
+func (s *indexStore) refresh() {
+	slugs, err := installedDocsets(s.cache)
+	if err != nil {
+		return
+	}
+
+	installed := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		installed[slug] = true
+
+		s.mu.RLock()
+		d, ok := s.docsets[slug]
+		s.mu.RUnlock()
+		if !ok || !s.docsetStamp(slug).Equal(d.stamp) {
+			s.load(slug)
+		}
+	}
+
+	s.mu.Lock()
+	for slug := range s.docsets {
+		if !installed[slug] {
+			delete(s.docsets, slug)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// daemonRequest is a request to the daemon, one per connection
+type daemonRequest struct {
+	Method  string   `json:"method"` // search, lookup or document
+	Query   string   `json:"query,omitempty"`
+	Symbol  string   `json:"symbol,omitempty"`
+	Docsets []string `json:"docsets,omitempty"`
+	Limit   int      `json:"limit,omitempty"`
+	Docset  string   `json:"docset,omitempty"`
+	Path    string   `json:"path,omitempty"`
+}
+
+type daemonResult struct {
+	Docset  string `json:"docset"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Score   int    `json:"score"`
+	Matches []int  `json:"matches,omitempty"`
+}
+
+type daemonResponse struct {
+	Results []daemonResult `json:"results,omitempty"`
+	Content string         `json:"content,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func (r daemonResponse) err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return errors.New(r.Error)
+}
+
+func (r daemonResponse) searchResults() []searchResult {
+	results := make([]searchResult, len(r.Results))
+	for i, result := range r.Results {
+		results[i] = searchResult{
+			docset:  result.Docset,
+			entry:   DocumentEntry{Name: result.Name, Path: result.Path, Type: result.Type},
+			matches: result.Matches,
+			score:   result.Score,
+		}
+	}
+	return results
+}
+
+func newDaemonResponse(results []searchResult, err error) daemonResponse {
+	var resp daemonResponse
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	for _, result := range results {
+		resp.Results = append(resp.Results, daemonResult{
+			Docset:  result.docset,
+			Name:    result.entry.Name,
+			Path:    result.entry.Path,
+			Type:    result.entry.Type,
+			Score:   result.score,
+			Matches: result.matches,
+		})
+	}
+	return resp
+}
+
+// callDaemon sends a request to the daemon. It reports false when no daemon
+// answers, so the caller does the work itself.
+func callDaemon(cache *Cache, req daemonRequest) (daemonResponse, bool) {
+	var resp daemonResponse
+	if cache.store != nil {
+		return resp, false // This is the daemon
+	}
+
+	conn, err := net.DialTimeout("unix", cache.daemonSocket(), daemonDialTimeout)
+	if err != nil {
+		return resp, false
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(daemonTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return resp, false
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return resp, false
+	}
+	return resp, true
+}
+
+// Daemon answers search, lookup and document requests from indexes kept in
+// memory
+type Daemon struct {
+	cache  *Cache
+	client *DevDoc
+}
+
+// NewDaemon creates a daemon serving the documentation of the cache, which
+// it gives an in-memory store
+func NewDaemon(cache *Cache) *Daemon {
+	cache.store = newIndexStore(cache)
+	return &Daemon{cache: cache, client: newDocs(cache)}
+}
+
+// Serve loads all installed docsets and answers requests on the daemon
+// socket until interrupted, reloading docsets as they change
+func (d *Daemon) Serve() error {
+	socket := d.cache.daemonSocket()
+	if conn, err := net.DialTimeout("unix", socket, daemonDialTimeout); err == nil {
+		conn.Close()
+		return fmt.Errorf("a daemon is already listening on %s", socket)
+	}
+	os.Remove(socket) // Left behind by a daemon that didn't shut down cleanly
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socket)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	d.cache.store.refresh()
+	go func() {
+		for range time.Tick(daemonPollInterval) {
+			d.cache.store.refresh()
+		}
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *Daemon) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(daemonTimeout))
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	var req daemonRequest
+	var resp daemonResponse
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else {
+		resp = d.answer(req)
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+func (d *Daemon) answer(req daemonRequest) daemonResponse {
+	switch req.Method {
+	case "search":
+		return newDaemonResponse(searchDocsets(d.cache, req.Query, req.Limit, req.Docsets...))
+	case "lookup":
+		result, err := lookupEntry(d.cache, req.Symbol, req.Docsets...)
+		if err != nil {
+			return newDaemonResponse(nil, err)
+		}
+		return newDaemonResponse([]searchResult{result}, nil)
+	case "document":
+		content, err := d.client.GetDocument(req.Docset, req.Path)
+		if err != nil {
+			return daemonResponse{Error: err.Error()}
+		}
+		return daemonResponse{Content: content}
+	}
+	return daemonResponse{Error: fmt.Sprintf("unknown method: %s", req.Method)}
+}
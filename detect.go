@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// projectDependency is a language or package a project declares
+type projectDependency struct {
+	Name       string
+	Version    string
+	Constraint string // As declared, e.g. ">=3.10"
+	Source     string // Manifest the dependency was found in
+}
+
+// pinned reports whether the dependency asks for its version specifically,
+// as ==, ~= and ^ do. Ranges such as >=3.10 and bare versions only set a
+// bound the installed version may well satisfy.
+func (d projectDependency) pinned() bool {
+	loc := versionNumberRe.FindStringIndex(d.Constraint)
+	if loc == nil {
+		return false
+	}
+	op := strings.TrimRight(d.Constraint[:loc[0]], `"' `)
+	return strings.HasSuffix(op, "==") || strings.HasSuffix(op, "~=") || strings.HasSuffix(op, "^")
+}
+
+// dependencyDocsets maps package names to the docsets documenting them.
+// Packages not listed here are matched against docset names directly.
+var dependencyDocsets = map[string]string{
+	"react-dom":                "react",
+	"@angular/core":            "angular",
+	"next":                     "next.js",
+	"node":                     "node.js",
+	"moment":                   "moment.js",
+	"d3":                       "d3.js",
+	"three":                    "three.js",
+	"tailwindcss":              "tailwind css",
+	"@reduxjs/toolkit":         "redux",
+	"laravel/framework":        "laravel",
+	"symfony/symfony":          "symfony",
+	"symfony/framework-bundle": "symfony",
+	"phpunit/phpunit":          "phpunit",
+	"drupal/core":              "drupal",
+	"codeigniter4/framework":   "codeigniter",
+	"torch":                    "pytorch",
+	"rails":                    "ruby on rails",
+}
+
+var (
+	versionNumberRe  = regexp.MustCompile(`\d+(\.\d+)*`)
+	requirementRe    = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(.*)$`)
+	gemRe            = regexp.MustCompile(`^\s*gem\s+['"]([^'"]+)['"]\s*(?:,\s*['"]([^'"]+)['"])?`)
+	rubyVersionRe    = regexp.MustCompile(`^\s*ruby\s+['"]([^'"]+)['"]`)
+	tomlStringRe     = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+	tomlKeyValueRe   = regexp.MustCompile(`^\s*([A-Za-z0-9_.-]+)\s*=\s*(.*)$`)
+	tomlSectionRe    = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`)
+	goDirectiveRe    = regexp.MustCompile(`^go\s+(\S+)`)
+	requiresPythonRe = regexp.MustCompile(`^\s*requires-python\s*=\s*["']([^"']*)["']`)
+)
+
+// versionNumber extracts the version number from a constraint such as
+// "^18.2.0" or ">=3.10"
+func versionNumber(constraint string) string {
+	return versionNumberRe.FindString(constraint)
+}
+
+// newDependency creates a dependency declared with a version constraint
+func newDependency(name, constraint, source string) projectDependency {
+	return projectDependency{
+		Name:       name,
+		Version:    versionNumber(constraint),
+		Constraint: constraint,
+		Source:     source,
+	}
+}
+
+// detectDependencies scans the manifests in dir for declared dependencies
+func detectDependencies(dir string) []projectDependency {
+	var deps []projectDependency
+	for _, detect := range []func(string) []projectDependency{
+		detectGoMod,
+		detectPackageJSON,
+		detectComposerJSON,
+		detectRequirementsTxt,
+		detectPyprojectTOML,
+		detectGemfile,
+	} {
+		deps = append(deps, detect(dir)...)
+	}
+	return deps
+}
+
+// readLines reads a manifest line by line, returning nil if it doesn't exist
+func readLines(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func detectGoMod(dir string) []projectDependency {
+	for _, line := range readLines(filepath.Join(dir, "go.mod")) {
+		if m := goDirectiveRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return []projectDependency{newDependency("go", m[1], "go.mod")}
+		}
+	}
+	return nil
+}
+
+func detectPackageJSON(dir string) []projectDependency {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+		Engines         map[string]string `json:"engines"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	deps := []projectDependency{{Name: "javascript", Source: "package.json"}}
+	if node, ok := manifest.Engines["node"]; ok {
+		deps = append(deps, newDependency("node", node, "package.json"))
+	}
+	for _, packages := range []map[string]string{manifest.Dependencies, manifest.DevDependencies} {
+		for name, constraint := range packages {
+			deps = append(deps, newDependency(name, constraint, "package.json"))
+		}
+	}
+	return deps
+}
+
+func detectComposerJSON(dir string) []projectDependency {
+	data, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	var deps []projectDependency
+	if _, ok := manifest.Require["php"]; !ok {
+		deps = append(deps, projectDependency{Name: "php", Source: "composer.json"})
+	}
+	for _, packages := range []map[string]string{manifest.Require, manifest.RequireDev} {
+		for name, constraint := range packages {
+			deps = append(deps, newDependency(name, constraint, "composer.json"))
+		}
+	}
+	return deps
+}
+
+// parseRequirement parses a PEP 508 requirement such as "Django>=4.2"
+func parseRequirement(requirement string, source string) (projectDependency, bool) {
+	requirement, _, _ = strings.Cut(requirement, ";")
+	requirement = strings.TrimSpace(requirement)
+	m := requirementRe.FindStringSubmatch(requirement)
+	if m == nil {
+		return projectDependency{}, false
+	}
+	return newDependency(strings.ToLower(m[1]), m[3], source), true
+}
+
+func detectRequirementsTxt(dir string) []projectDependency {
+	lines := readLines(filepath.Join(dir, "requirements.txt"))
+	if lines == nil {
+		return nil
+	}
+
+	deps := []projectDependency{{Name: "python", Source: "requirements.txt"}}
+	for _, line := range lines {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if dep, ok := parseRequirement(line, "requirements.txt"); ok {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// detectPyprojectTOML reads requires-python, the PEP 621 dependencies array
+// and poetry's dependency table. It is not a full TOML parser.
+func detectPyprojectTOML(dir string) []projectDependency {
+	lines := readLines(filepath.Join(dir, "pyproject.toml"))
+	if lines == nil {
+		return nil
+	}
+
+	python := projectDependency{Name: "python", Source: "pyproject.toml"}
+	var deps []projectDependency
+	section := ""
+	inDependencies := false
+
+	for _, line := range lines {
+		if m := tomlSectionRe.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			inDependencies = false
+			continue
+		}
+
+		if m := requiresPythonRe.FindStringSubmatch(line); m != nil {
+			python = newDependency("python", m[1], "pyproject.toml")
+			continue
+		}
+
+		if section == "project" && strings.HasPrefix(strings.TrimSpace(line), "dependencies") {
+			inDependencies = true
+		}
+		if inDependencies {
+			for _, m := range tomlStringRe.FindAllStringSubmatch(line, -1) {
+				if dep, ok := parseRequirement(m[1]+m[2], "pyproject.toml"); ok {
+					deps = append(deps, dep)
+				}
+			}
+			if strings.Contains(line, "]") {
+				inDependencies = false
+			}
+			continue
+		}
+
+		if section == "tool.poetry.dependencies" {
+			if m := tomlKeyValueRe.FindStringSubmatch(line); m != nil {
+				dep := newDependency(strings.ToLower(m[1]), m[2], "pyproject.toml")
+				if dep.Name == "python" {
+					python = dep
+				} else {
+					deps = append(deps, dep)
+				}
+			}
+		}
+	}
+
+	return append([]projectDependency{python}, deps...)
+}
+
+func detectGemfile(dir string) []projectDependency {
+	lines := readLines(filepath.Join(dir, "Gemfile"))
+	if lines == nil {
+		return nil
+	}
+
+	ruby := projectDependency{Name: "ruby", Source: "Gemfile"}
+	var deps []projectDependency
+	for _, line := range lines {
+		if m := rubyVersionRe.FindStringSubmatch(line); m != nil {
+			ruby = newDependency("ruby", m[1], "Gemfile")
+			continue
+		}
+		if m := gemRe.FindStringSubmatch(line); m != nil {
+			deps = append(deps, newDependency(m[1], m[2], "Gemfile"))
+		}
+	}
+	return append([]projectDependency{ruby}, deps...)
+}
+
+// findCatalogDoc returns the catalog family documenting a dependency
+func findCatalogDoc(catalog []Documentation, dep projectDependency) (Documentation, bool) {
+	name := strings.ToLower(dep.Name)
+	if mapped, ok := dependencyDocsets[name]; ok {
+		name = mapped
+	}
+
+	for _, doc := range catalog {
+		base, _, _ := strings.Cut(doc.Slug, "~")
+		if doc.Kind() == name || base == name {
+			return doc, true
+		}
+	}
+	return Documentation{}, false
+}
+
+// closestVersion picks the version of a docset family best matching a
+// dependency version: the most specific release the version falls in,
+// else the newest one not above it, else the newest one overall
+func closestVersion(doc Documentation, version string) Documentation {
+	versions := doc.ListVersions()
+	if len(versions) == 0 || version == "" {
+		return doc.GetLatestVersion()
+	}
+
+	var best *Documentation
+	for i := range versions {
+		v := versions[i].Version
+		if v != "" && (version == v || strings.HasPrefix(version, v+".")) {
+			if best == nil || len(v) > len(best.Version) {
+				best = &versions[i]
+			}
+		}
+	}
+	if best != nil {
+		return *best
+	}
+
+	for i := range versions {
+		v := versions[i].Version
+		if v != "" && CompareVersions(v, version) <= 0 {
+			if best == nil || CompareVersions(v, best.Version) > 0 {
+				best = &versions[i]
+			}
+		}
+	}
+	if best != nil {
+		return *best
+	}
+	return doc.GetLatestVersion()
+}
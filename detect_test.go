@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestProjectDependencyPinned(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		pinned     bool
+	}{
+		{">=3.10", "3.10", false},
+		{">3.10,<4", "3.10", false},
+		{"==4.2", "4.2", true},
+		{"~=4.2.1", "4.2.1", true},
+		{"^18.2.0", "18.2.0", true},
+		{`"^3.11"`, "3.11", true},
+		{`{version = "^1.26", optional = true}`, "1.26", true},
+		{"~> 7.1", "7.1", false},
+		{"1.22", "1.22", false},
+		{"*", "", false},
+	}
+	for _, tt := range tests {
+		dep := newDependency("python", tt.constraint, "pyproject.toml")
+		if dep.Version != tt.version || dep.pinned() != tt.pinned {
+			t.Errorf("newDependency(%q) = version %q, pinned %v; want %q, %v", tt.constraint, dep.Version, dep.pinned(), tt.version, tt.pinned)
+		}
+	}
+}
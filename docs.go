@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 type DevDoc struct {
@@ -17,8 +18,10 @@ func newDocs(cache *Cache) *DevDoc {
 	return &DevDoc{cache: cache}
 }
 
-func (c *DevDoc) DownloadDocSet(docset *Documentation) error {
-	if err := c.cache.EnsureDir(docset.Kind()); err != nil {
+// DownloadDocSetTo installs a docset into a directory of the cache, the one
+// picked by versionDir unless it replaces a specific installation
+func (c *DevDoc) DownloadDocSetTo(docset *Documentation, dir string) error {
+	if err := c.cache.EnsureDir(dir); err != nil {
 		return err
 	}
 
@@ -26,7 +29,7 @@ func (c *DevDoc) DownloadDocSet(docset *Documentation) error {
 	if err := c.downloadFile(
 		fmt.Sprintf("https://devdocs.io/docs/%s/index.json?%d",
 			docset.Slug, docset.Mtime),
-		filepath.Join(c.cache.GetDocPath(docset.Kind()), "index.json"),
+		filepath.Join(c.cache.GetDocPath(dir), "index.json"),
 	); err != nil {
 		return err
 	}
@@ -35,12 +38,13 @@ func (c *DevDoc) DownloadDocSet(docset *Documentation) error {
 	if err := c.downloadFile(
 		fmt.Sprintf("https://documents.devdocs.io/%s/db.json?%d",
 			docset.Slug, docset.Mtime),
-		filepath.Join(c.cache.GetDocPath(docset.Kind()), "db.json"),
+		filepath.Join(c.cache.GetDocPath(dir), "db.json"),
 	); err != nil {
 		return err
 	}
 
-	if err := c.cache.SaveMeta(docset.Kind(), DocMeta{
+	if err := c.cache.SaveMeta(dir, DocMeta{
+		Slug:    docset.Slug,
 		Release: docset.Release,
 		Version: docset.Version,
 		Mtime:   docset.Mtime,
@@ -49,7 +53,16 @@ func (c *DevDoc) DownloadDocSet(docset *Documentation) error {
 	}
 
 	// Unpack documentation into HTML files
-	return c.unpackHTML(docset.Kind())
+	if err := c.unpackHTML(dir); err != nil {
+		return err
+	}
+
+	// Point bookmarks at the entries of the new index
+	entries, err := c.GetDocumentation(dir)
+	if err != nil {
+		return err
+	}
+	return c.cache.ResolveBookmarks(dir, entries)
 }
 
 func (c *DevDoc) downloadFile(url, filepath string) error {
@@ -85,7 +98,20 @@ func (c *DevDoc) GetDocumentation(slug string) ([]DocumentEntry, error) {
 	return index.Entries, nil
 }
 
+// GetDocument returns the HTML of a document. The daemon reads it at its
+// offset in db.json, and answers for other processes when it is running.
 func (c *DevDoc) GetDocument(slug, path string) (string, error) {
+	if c.cache.store != nil {
+		return c.cache.store.document(slug, path)
+	}
+	if resp, ok := callDaemon(c.cache, daemonRequest{
+		Method: "document",
+		Docset: slug,
+		Path:   path,
+	}); ok {
+		return resp.Content, resp.err()
+	}
+
 	data, err := c.cache.GetDB(slug)
 	if err != nil {
 		return "", fmt.Errorf("failed to read db.json: %w", err)
@@ -125,12 +151,48 @@ func (c *DevDoc) unpackHTML(slug string) error {
 		return fmt.Errorf("failed to parse db.json: %w", err)
 	}
 
+	if err := c.cache.EnsureAssetsDir(slug); err != nil {
+		return fmt.Errorf("failed to create assets directory: %w", err)
+	}
+	links := &docsetLinks{
+		pages:     make(map[string]bool, len(docs)),
+		installed: c.cache.InstalledSlugs(),
+		catalog:   catalogSlugs(c.cache),
+		assets:    newAssetFetcher(c.cache, slug),
+	}
+	if meta, err := c.cache.GetMeta(slug); err == nil {
+		links.slug = meta.Slug
+	}
+	for path := range docs {
+		links.pages[path] = true
+	}
+
+	entries, err := c.GetDocumentation(slug)
+	if err != nil {
+		return err
+	}
+	anchors := pageEntries(entries)
+
+	paths := make([]string, 0, len(docs))
+	for path := range docs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
 	// Process each documentation entry
-	for path, content := range docs {
-		if err := c.cache.SaveHTML(slug, path, content); err != nil {
+	var sections []Section
+	for _, path := range paths {
+		content := docs[path]
+		if err := c.cache.SaveHTML(slug, path, content, links); err != nil {
 			return fmt.Errorf("failed to save HTML for %s: %w", path, err)
 		}
+
+		found, err := extractSections(path, content, anchors[path])
+		if err != nil {
+			return fmt.Errorf("failed to extract sections of %s: %w", path, err)
+		}
+		sections = append(sections, found...)
 	}
 
-	return nil
+	return c.cache.SaveSections(slug, sections)
 }
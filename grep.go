@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// grepOptions control how matches of ddc grep are printed
+type grepOptions struct {
+	before  int  // Lines of context before a match
+	after   int  // Lines of context after a match
+	vimgrep bool // Print file:line:col of the HTML files instead
+}
+
+// grepDocsets searches the text of the pages of docsets in parallel and
+// writes the matches in docset order. It reports whether anything matched.
+func grepDocsets(cache *Cache, re *regexp.Regexp, opts grepOptions, docsets []string, w io.Writer) (bool, error) {
+	type docsetOutput struct {
+		out     bytes.Buffer
+		matched bool
+		err     error
+	}
+	outputs := make([]docsetOutput, len(docsets))
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, slug := range docsets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			o := &outputs[i]
+			o.matched, o.err = grepDocset(cache, slug, re, opts, &o.out)
+		}()
+	}
+	wg.Wait()
+
+	matched := false
+	for i, o := range outputs {
+		if o.err != nil {
+			return matched, fmt.Errorf("failed to search %s: %w", docsets[i], o.err)
+		}
+		if _, err := o.out.WriteTo(w); err != nil {
+			return matched, err
+		}
+		matched = matched || o.matched
+	}
+	return matched, nil
+}
+
+// grepDocset searches the pages of a docset in path order
+func grepDocset(cache *Cache, slug string, re *regexp.Regexp, opts grepOptions, w io.Writer) (bool, error) {
+	data, err := cache.GetDB(slug)
+	if err != nil {
+		return false, fmt.Errorf("failed to read db.json: %w", err)
+	}
+
+	var docs map[string]string
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return false, fmt.Errorf("failed to parse db.json: %w", err)
+	}
+
+	entries, err := newDocs(cache).GetDocumentation(slug)
+	if err != nil {
+		return false, err
+	}
+	anchors := pageEntries(entries)
+
+	paths := make([]string, 0, len(docs))
+	for path := range docs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	matched := false
+	for _, path := range paths {
+		var found bool
+		if opts.vimgrep {
+			file, _ := cache.GetHTMLPath(slug, path)
+			found, err = grepHTMLFile(file, re, w)
+		} else {
+			found, err = grepPage(pageName(path, anchors[path]), slug, docs[path], re, opts, w)
+		}
+		if err != nil {
+			return matched, err
+		}
+		matched = matched || found
+	}
+	return matched, nil
+}
+
+// pageName names a page after its entry, or its first one
+func pageName(path string, anchors map[string]string) string {
+	if name, ok := anchors[""]; ok {
+		return name
+	}
+	name := ""
+	for _, n := range anchors {
+		if name == "" || n < name {
+			name = n
+		}
+	}
+	if name == "" {
+		return path
+	}
+	return name
+}
+
+// grepPage matches the lines of the rendered text of a page and prints them
+// as docset:entry:line: text, with context lines as docset:entry-line- text
+// and -- between groups that aren't adjacent. Context counts the lines with
+// text, skipping the blank lines between paragraphs.
+func grepPage(name, slug, content string, re *regexp.Regexp, opts grepOptions, w io.Writer) (bool, error) {
+	text, err := htmlToText(content, "")
+	if err != nil {
+		return false, err
+	}
+
+	type textLine struct {
+		number int
+		text   string
+	}
+	var lines []textLine
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, textLine{i + 1, line})
+		}
+	}
+
+	matched := false
+	last := -1 // Last line printed
+	for i, line := range lines {
+		if !re.MatchString(line.text) {
+			continue
+		}
+		matched = true
+
+		from := max(i-opts.before, last+1)
+		if last >= 0 && from > last+1 && (opts.before > 0 || opts.after > 0) {
+			fmt.Fprintln(w, "--")
+		}
+		for j := from; j < i; j++ {
+			fmt.Fprintf(w, "%s:%s-%d- %s\n", slug, name, lines[j].number, lines[j].text)
+		}
+		fmt.Fprintf(w, "%s:%s:%d: %s\n", slug, name, line.number, line.text)
+		last = i
+
+		// Context after stops at the next match, which prints its own
+		for j := i + 1; j <= min(i+opts.after, len(lines)-1) && !re.MatchString(lines[j].text); j++ {
+			fmt.Fprintf(w, "%s:%s-%d- %s\n", slug, name, lines[j].number, lines[j].text)
+			last = j
+		}
+	}
+	return matched, nil
+}
+
+// grepHTMLFile matches the text of an unpacked HTML file, skipping tags,
+// scripts and styles, and prints file:line:col:text of each match for vim's
+// quickfix list
+func grepHTMLFile(file string, re *regexp.Regexp, w io.Writer) (bool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	matched := false
+	line, lineStart, counted := 1, 0, 0
+	position := func(offset int) (int, int) {
+		for ; counted < offset; counted++ {
+			if data[counted] == '\n' {
+				line++
+				lineStart = counted + 1
+			}
+		}
+		return line, offset - lineStart + 1
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(data))
+	offset, skip := 0, 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				return matched, nil
+			}
+			return matched, z.Err()
+		}
+		raw := z.Raw()
+		start := offset
+		offset += len(raw)
+
+		switch tt {
+		case html.StartTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			if a := atom.Lookup(name); a == atom.Script || a == atom.Style {
+				if tt == html.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+		case html.TextToken:
+			if skip > 0 || !re.MatchString(html.UnescapeString(string(raw))) {
+				continue
+			}
+			matched = true
+
+			// Point at the match in the raw text, or the start of the text
+			// when only entities decoded make it match
+			locs := re.FindAllIndex(raw, -1)
+			if len(locs) == 0 {
+				locs = [][]int{{0, 0}}
+			}
+			for _, loc := range locs {
+				l, col := position(start + loc[0])
+				end := bytes.IndexByte(data[lineStart:], '\n')
+				if end < 0 {
+					end = len(data) - lineStart
+				}
+				text := strings.TrimSpace(string(data[lineStart : lineStart+end]))
+				fmt.Fprintf(w, "%s:%d:%d:%s\n", file, l, col, text)
+			}
+		}
+	}
+}
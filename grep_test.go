@@ -0,0 +1,66 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGrepPageContext(t *testing.T) {
+	page := `<h1>os.path</h1>
+<p>This module implements some useful functions on pathnames.</p>
+<dl><dt id="os.path.join">os.path.join(path, *paths)</dt>
+<dd><p>Join one or more path segments intelligently.</p>
+<p>On Windows, the drive is not reset when a rooted path segment is encountered.</p></dd></dl>
+<p>Changed in version 3.6: Accepts a path-like object.</p>`
+
+	tests := []struct {
+		name    string
+		pattern string
+		opts    grepOptions
+		want    []string
+	}{
+		{
+			name:    "match",
+			pattern: "segments",
+			want:    []string{"python:os.path:7:     Join one or more path segments intelligently."},
+		},
+		{
+			name:    "context skips blank lines",
+			pattern: "segments",
+			opts:    grepOptions{before: 1, after: 1},
+			want: []string{
+				"python:os.path-5- os.path.join(path, *paths)",
+				"python:os.path:7:     Join one or more path segments intelligently.",
+				"python:os.path-9-     On Windows, the drive is not reset when a rooted path segment is encountered.",
+			},
+		},
+		{
+			name:    "separated groups",
+			pattern: "module|3\\.6",
+			opts:    grepOptions{after: 1},
+			want: []string{
+				"python:os.path:3: This module implements some useful functions on pathnames.",
+				"python:os.path-5- os.path.join(path, *paths)",
+				"--",
+				"python:os.path:11: Changed in version 3.6: Accepts a path-like object.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			matched, err := grepPage("os.path", "python", page, regexp.MustCompile(tt.pattern), tt.opts, &out)
+			if err != nil {
+				t.Fatalf("grepPage() error = %v", err)
+			}
+			if !matched {
+				t.Fatal("grepPage() matched nothing")
+			}
+			if got, want := strings.TrimRight(out.String(), "\n"), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("grepPage() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
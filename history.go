@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/list"
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// maxVisits is how many visits are kept in the history file
+const maxVisits = 1000
+
+type Visit struct {
+	Docset string    `json:"docset"`
+	Path   string    `json:"path"`
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	Time   time.Time `json:"time"`
+}
+
+func (v Visit) Entry() DocumentEntry {
+	return DocumentEntry{Name: v.Name, Path: v.Path, Type: v.Type}
+}
+
+func (c *Cache) historyPath() string {
+	return filepath.Join(c.BaseDir, "history.json")
+}
+
+// GetHistory returns all recorded visits, oldest first
+func (c *Cache) GetHistory() ([]Visit, error) {
+	data, err := os.ReadFile(c.historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var visits []Visit
+	if err := json.Unmarshal(data, &visits); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return visits, nil
+}
+
+// RecordVisit appends an opened entry to the history
+func (c *Cache) RecordVisit(docset string, entry DocumentEntry) error {
+	visits, err := c.GetHistory()
+	if err != nil {
+		return err
+	}
+
+	visits = append(visits, Visit{
+		Docset: docset,
+		Path:   entry.Path,
+		Name:   entry.Name,
+		Type:   entry.Type,
+		Time:   time.Now(),
+	})
+	if len(visits) > maxVisits {
+		visits = visits[len(visits)-maxVisits:]
+	}
+
+	if err := os.MkdirAll(c.BaseDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(visits)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.historyPath(), data, 0644)
+}
+
+func (c *Cache) ClearHistory() error {
+	err := os.Remove(c.historyPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// RecentVisits returns the latest visit of each entry, most recent first
+func (c *Cache) RecentVisits() ([]Visit, error) {
+	visits, err := c.GetHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var recent []Visit
+	for i := len(visits) - 1; i >= 0; i-- {
+		key := frecencyKey(visits[i].Docset, visits[i].Path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		recent = append(recent, visits[i])
+	}
+	return recent, nil
+}
+
+// visitWeight scores a single visit by how long ago it happened
+func visitWeight(age time.Duration) int {
+	day := 24 * time.Hour
+	switch {
+	case age < 4*day:
+		return 100
+	case age < 14*day:
+		return 70
+	case age < 31*day:
+		return 50
+	case age < 90*day:
+		return 30
+	default:
+		return 10
+	}
+}
+
+// Frecency scores entries by how often and how recently they were opened.
+// Keys are docset and path joined by a NUL byte, see frecencyKey.
+func (c *Cache) Frecency() map[string]int {
+	scores := make(map[string]int)
+
+	visits, err := c.GetHistory()
+	if err != nil {
+		return scores
+	}
+
+	now := time.Now()
+	for _, v := range visits {
+		scores[frecencyKey(v.Docset, v.Path)] += visitWeight(now.Sub(v.Time))
+	}
+	return scores
+}
+
+func frecencyKey(docset, path string) string {
+	return docset + "\x00" + path
+}
+
+// formatAge formats how long ago a time was, e.g. "5m ago"
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+type visitItem Visit
+
+func (i visitItem) FilterValue() string { return i.Name }
+
+type visitDelegate struct{}
+
+func (d visitDelegate) Height() int                             { return 1 }
+func (d visitDelegate) Spacing() int                            { return 0 }
+func (d visitDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d visitDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(visitItem)
+	if !ok {
+		return
+	}
+
+	str := fmt.Sprintf("%s: %s (%s)", i.Docset, i.Name, formatAge(i.Time))
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = selectedItemStyle.Render
+	}
+
+	fmt.Fprint(w, fn(str))
+}
+
+type RecentModel struct {
+	list  list.Model
+	cache *Cache
+	err   error
+}
+
+func NewRecentModel(cache *Cache, visits []Visit) RecentModel {
+	items := make([]list.Item, len(visits))
+	for i, v := range visits {
+		items[i] = visitItem(v)
+	}
+
+	l := list.New(items, visitDelegate{}, 80, 30)
+	l.Title = "Recently opened"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = titleStyle
+	l.Styles.PaginationStyle = paginationStyle
+	l.Styles.HelpStyle = helpStyle
+
+	return RecentModel{
+		list:  l,
+		cache: cache,
+	}
+}
+
+func (m RecentModel) Init() (tea.Model, tea.Cmd) {
+	return m, nil
+}
+
+func (m RecentModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height-4)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "o", "enter":
+			if m.list.SettingFilter() {
+				break
+			}
+			if i, ok := m.list.SelectedItem().(visitItem); ok {
+				if err := openEntry(m.cache, i.Docset, Visit(i).Entry()); err != nil {
+					m.err = err
+					return m, nil
+				}
+				return m, tea.Quit
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m RecentModel) View() string {
+	view := "\n" + m.list.View()
+	if m.err != nil {
+		view += "\nError: " + m.err.Error()
+	}
+	return view
+}
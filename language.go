@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultLanguages maps file extensions and shebang interpreters to the
+// devdocs slugs, without version, of the docsets relevant for them, in
+// order of preference
+var defaultLanguages = map[string][]string{
+	".c":     {"c"},
+	".h":     {"c", "cpp"},
+	".cc":    {"cpp"},
+	".cpp":   {"cpp"},
+	".hpp":   {"cpp"},
+	".css":   {"css"},
+	".scss":  {"sass", "css"},
+	".go":    {"go"},
+	".html":  {"html", "dom"},
+	".java":  {"openjdk"},
+	".js":    {"javascript", "dom", "node"},
+	".mjs":   {"javascript", "node"},
+	".cjs":   {"javascript", "node"},
+	".jsx":   {"react", "javascript", "dom"},
+	".ts":    {"typescript", "javascript", "dom"},
+	".tsx":   {"typescript", "react", "javascript", "dom"},
+	".vue":   {"vue", "javascript", "dom"},
+	".lua":   {"lua"},
+	".php":   {"php", "wordpress"},
+	".pl":    {"perl"},
+	".py":    {"python"},
+	".rb":    {"ruby"},
+	".rs":    {"rust"},
+	".sh":    {"bash"},
+	".bash":  {"bash"},
+	".sql":   {"postgresql", "sqlite"},
+	".swift": {"swift"},
+	".kt":    {"kotlin"},
+	".ex":    {"elixir"},
+	".exs":   {"elixir"},
+	".erl":   {"erlang"},
+	".hs":    {"haskell"},
+	".zig":   {"zig"},
+	"bash":   {"bash"},
+	"sh":     {"bash"},
+	"zsh":    {"bash"},
+	"node":   {"javascript", "node"},
+	"deno":   {"typescript", "javascript", "deno"},
+	"php":    {"php"},
+	"perl":   {"perl"},
+	"python": {"python"},
+	"ruby":   {"ruby"},
+	"lua":    {"lua"},
+}
+
+// Languages returns the extension and interpreter to docset mapping, with
+// entries from languages.json in the cache directory taking precedence
+func (c *Cache) Languages() (map[string][]string, error) {
+	languages := make(map[string][]string, len(defaultLanguages))
+	for key, docsets := range defaultLanguages {
+		languages[key] = docsets
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.BaseDir, "languages.json"))
+	if os.IsNotExist(err) {
+		return languages, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides map[string][]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse languages.json: %w", err)
+	}
+	for key, docsets := range overrides {
+		languages[strings.ToLower(key)] = docsets
+	}
+	return languages, nil
+}
+
+// shebangInterpreter returns the interpreter named in a file's shebang
+// line without version suffix, e.g. "python" for "#!/usr/bin/env python3"
+func shebangInterpreter(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	line, ok := strings.CutPrefix(line, "#!")
+	if !ok {
+		return ""
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	return strings.TrimRight(interpreter, "0123456789.")
+}
+
+// languageDocsets returns the installed docsets relevant for a file, judged
+// by its extension and otherwise by its shebang
+func languageDocsets(cache *Cache, file string) ([]string, error) {
+	languages, err := cache.Languages()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := languages[strings.ToLower(filepath.Ext(file))]
+	if len(candidates) == 0 {
+		candidates = languages[shebangInterpreter(file)]
+	}
+
+	// Candidates are slugs or names, installed in directories named
+	// differently, e.g. cpp in c++
+	installed := cache.InstalledSlugs()
+
+	var docsets []string
+	seen := make(map[string]bool)
+	for _, slug := range candidates {
+		dir, ok := installed[strings.ToLower(slug)]
+		if !ok || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		docsets = append(docsets, dir)
+	}
+	return docsets, nil
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// devdocsURL is where pages of docsets that aren't installed are linked to
+const devdocsURL = "https://devdocs.io/"
+
+// docsetLinks describes the docset being unpacked and the other installed
+// docsets, so links between them can be resolved
+type docsetLinks struct {
+	slug      string            // devdocs slug of the docset, e.g. "python~3.12"
+	pages     map[string]bool   // docset paths of every page
+	installed map[string]string // devdocs slug -> installed directory
+	catalog   map[string]bool   // devdocs slugs in the cached catalog
+	assets    *assetFetcher     // optional, downloads remote images
+}
+
+// catalogSlugs returns the slugs of every docset version in the cached
+// catalog, with and without their version. It is empty when the catalog
+// was never fetched.
+func catalogSlugs(cache *Cache) map[string]bool {
+	slugs := make(map[string]bool)
+	catalog, err := CachedDocumentations(cache)
+	if err != nil {
+		return slugs
+	}
+	for _, doc := range catalog {
+		for _, version := range doc.ListVersions() {
+			slugs[version.Slug] = true
+			base, _, _ := strings.Cut(version.Slug, "~")
+			slugs[base] = true
+		}
+	}
+	return slugs
+}
+
+// linkRewriter points the links of a single page at the unpacked files
+type linkRewriter struct {
+	cache *Cache
+	slug  string
+	page  string // docset path of the page being rewritten
+	links *docsetLinks
+}
+
+// rewriteLinks parses a page and rewrites its href, src and srcset
+// attributes so they resolve against the unpacked HTML tree
+func (c *Cache) rewriteLinks(slug, page, content string, links *docsetLinks) (string, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return "", err
+	}
+
+	if links == nil {
+		links = &docsetLinks{}
+	}
+	r := linkRewriter{cache: c, slug: slug, page: page, links: links}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		r.walk(n)
+		if err := html.Render(&buf, n); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+func (r linkRewriter) walk(n *html.Node) {
+	if n.Type == html.ElementNode {
+		for i, attr := range n.Attr {
+			switch attr.Key {
+			case "href":
+				r.rewriteHref(n, i)
+			case "src":
+				n.Attr[i].Val = r.rewriteSrc(attr.Val, isImage(n))
+			case "srcset":
+				n.Attr[i].Val = r.rewriteSrcset(attr.Val, isImage(n))
+			}
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		r.walk(child)
+	}
+}
+
+// rewriteHref turns a link to another page of the docset into a relative
+// link to its HTML file. Links into other docsets point at their local
+// copy when installed and at devdocs.io otherwise.
+func (r linkRewriter) rewriteHref(n *html.Node, i int) {
+	ref := n.Attr[i].Val
+	if strings.HasSuffix(ref, ".html") {
+		return
+	}
+
+	target, fragment, ok := resolveDocPath(r.page, ref)
+	if !ok {
+		return
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(ref), "/") {
+		other, rest := r.crossDocset(target)
+		if other != "" {
+			if dir, installed := r.links.installed[other]; installed {
+				htmlPath, _ := r.cache.GetHTMLPath(dir, rest)
+				n.Attr[i].Val = r.relativeTo(htmlPath, ref) + fragment
+				return
+			}
+
+			n.Attr[i].Val = devdocsURL + target + fragment
+			markNotInstalled(n, other)
+			return
+		}
+		target = rest
+	}
+
+	htmlPath, _ := r.cache.GetHTMLPath(r.slug, target)
+	n.Attr[i].Val = r.relativeTo(htmlPath, ref) + fragment
+}
+
+// crossDocset splits an absolute docset path into the slug of the docset it
+// belongs to and the path inside it. The slug is empty when the path points
+// into the current docset, which includes any path whose first segment is
+// neither an installed docset nor one in the catalog.
+func (r linkRewriter) crossDocset(target string) (string, string) {
+	if r.links.pages[target] || target == "index" {
+		return "", target
+	}
+
+	first, rest, _ := strings.Cut(target, "/")
+	if rest == "" {
+		rest = "index"
+	}
+
+	base, _, _ := strings.Cut(r.links.slug, "~")
+	if first == r.slug || first == r.links.slug || first == base {
+		return "", rest
+	}
+
+	if _, installed := r.links.installed[first]; installed || r.links.catalog[first] {
+		return first, rest
+	}
+	return "", target
+}
+
+// markNotInstalled flags a link to a docset that isn't installed locally
+func markNotInstalled(n *html.Node, slug string) {
+	title := fmt.Sprintf("%s is not installed. Run 'ddc download %s' or read it on devdocs.io", slug, slug)
+
+	hasClass := false
+	for i, attr := range n.Attr {
+		switch attr.Key {
+		case "class":
+			n.Attr[i].Val = strings.TrimSpace(attr.Val + " ddc-not-installed")
+			hasClass = true
+		case "title":
+			n.Attr[i].Val = title
+			title = ""
+		}
+	}
+	if !hasClass {
+		n.Attr = append(n.Attr, html.Attribute{Key: "class", Val: "ddc-not-installed"})
+	}
+	if title != "" {
+		n.Attr = append(n.Attr, html.Attribute{Key: "title", Val: title})
+	}
+}
+
+// isImage reports whether the src of an element is an image, which may be
+// downloaded for offline use. Frames and scripts keep their remote URL.
+func isImage(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Img, atom.Source, atom.Picture:
+		return true
+	}
+	return false
+}
+
+// rewriteSrc points an image either at its downloaded copy or at the file
+// it resolves to inside the docset. Only images are downloaded.
+func (r linkRewriter) rewriteSrc(ref string, image bool) string {
+	if isRemoteURL(ref) {
+		if !image || r.links.assets == nil {
+			return ref
+		}
+		pagePath, _ := r.cache.GetHTMLPath(r.slug, r.page)
+		return r.links.assets.localURL(filepath.Dir(pagePath), ref)
+	}
+
+	target, fragment, ok := resolveDocPath(r.page, ref)
+	if !ok {
+		return ref
+	}
+
+	return r.relativeTo(filepath.Join(r.cache.GetHTMLDir(r.slug), filepath.FromSlash(target)), ref) + fragment
+}
+
+// rewriteSrcset rewrites each "url descriptor" candidate of a srcset
+func (r linkRewriter) rewriteSrcset(value string, image bool) string {
+	candidates := strings.Split(value, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = r.rewriteSrc(fields[0], image)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// relativeTo returns target relative to the current page's file, falling
+// back to the original reference
+func (r linkRewriter) relativeTo(target, ref string) string {
+	pagePath, _ := r.cache.GetHTMLPath(r.slug, r.page)
+	rel, err := filepath.Rel(filepath.Dir(pagePath), target)
+	if err != nil {
+		return ref
+	}
+	return filepath.ToSlash(rel)
+}
+
+// resolveDocPath resolves ref against the docset path of page the way a
+// browser would on devdocs.io. It reports false for external links and
+// links that stay on the same page.
+func resolveDocPath(page, ref string) (string, string, bool) {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Opaque != "" {
+		return "", "", false
+	}
+
+	// Links like "#section" or "" stay on the current page
+	if u.Path == "" {
+		return "", "", false
+	}
+
+	base := &url.URL{Path: "/" + page}
+	resolved := base.ResolveReference(u)
+
+	target := strings.Trim(resolved.Path, "/")
+	if target == "" || target == "." {
+		target = "index"
+	}
+
+	var fragment string
+	if resolved.Fragment != "" {
+		fragment = "#" + resolved.EscapedFragment()
+	}
+	return target, fragment, true
+}
+
+// isRemoteURL reports whether ref points at another host
+func isRemoteURL(ref string) bool {
+	return strings.HasPrefix(ref, "http://") ||
+		strings.HasPrefix(ref, "https://") ||
+		strings.HasPrefix(ref, "//")
+}
@@ -0,0 +1,140 @@
+package main
+
+import "testing"
+
+func TestRewriteLinks(t *testing.T) {
+	cache := &Cache{BaseDir: "/cache"}
+	links := &docsetLinks{
+		slug: "python~3.12",
+		pages: map[string]bool{
+			"library/functions": true,
+			"library/os.path":   true,
+			"library/stdtypes":  true,
+			"reference/index":   true,
+		},
+		installed: map[string]string{"javascript": "javascript"},
+		catalog:   map[string]bool{"javascript": true, "dom": true},
+	}
+
+	tests := []struct {
+		name    string
+		page    string
+		content string
+		want    string
+	}{
+		{
+			name:    "double-quoted href",
+			page:    "library/functions",
+			content: `<a class="reference internal" href="stdtypes#str" title="str"><code class="xref py py-class docutils literal notranslate"><span class="pre">str</span></code></a>`,
+			want:    `<a class="reference internal" href="stdtypes.html#str" title="str"><code class="xref py py-class docutils literal notranslate"><span class="pre">str</span></code></a>`,
+		},
+		{
+			name:    "single-quoted href",
+			page:    "library/functions",
+			content: `<a href='stdtypes#str.join'>str.join()</a>`,
+			want:    `<a href="stdtypes.html#str.join">str.join()</a>`,
+		},
+		{
+			name:    "unquoted href",
+			page:    "library/functions",
+			content: `<a href=stdtypes#typesseq>Sequence Types</a>`,
+			want:    `<a href="stdtypes.html#typesseq">Sequence Types</a>`,
+		},
+		{
+			name:    "dotted path",
+			page:    "library/functions",
+			content: `<a class="reference internal" href="os.path#os.path.join" title="os.path.join"><code class="xref py py-func docutils literal notranslate"><span class="pre">os.path.join()</span></code></a>`,
+			want:    `<a class="reference internal" href="os/path.html#os.path.join" title="os.path.join"><code class="xref py py-func docutils literal notranslate"><span class="pre">os.path.join()</span></code></a>`,
+		},
+		{
+			name:    "parent directory",
+			page:    "library/functions",
+			content: `<a class="reference internal" href="../reference/index#reference-index">The Python Language Reference</a>`,
+			want:    `<a class="reference internal" href="../reference/index.html#reference-index">The Python Language Reference</a>`,
+		},
+		{
+			name:    "absolute path in the docset",
+			page:    "library/os.path",
+			content: `<a href="/library/functions#open">open()</a>`,
+			want:    `<a href="../functions.html#open">open()</a>`,
+		},
+		{
+			name:    "absolute path missing from the docset",
+			page:    "library/os.path",
+			content: `<a href="/library/missing_page">missing</a>`,
+			want:    `<a href="../missing_page.html">missing</a>`,
+		},
+		{
+			name:    "installed docset",
+			page:    "library/functions",
+			content: `<a href="/javascript/global_objects/array/join">Array.prototype.join()</a>`,
+			want:    `<a href="../../../javascript/html/global_objects/array/join.html">Array.prototype.join()</a>`,
+		},
+		{
+			name:    "docset in the catalog",
+			page:    "library/functions",
+			content: `<a href="/dom/element#attributes">Element</a>`,
+			want:    `<a href="https://devdocs.io/dom/element#attributes" class="ddc-not-installed" title="dom is not installed. Run &#39;ddc download dom&#39; or read it on devdocs.io">Element</a>`,
+		},
+		{
+			name:    "fragment only",
+			page:    "library/os.path",
+			content: `<a class="headerlink" href="#os.path.join" title="Link to this definition">¶</a>`,
+			want:    `<a class="headerlink" href="#os.path.join" title="Link to this definition">¶</a>`,
+		},
+		{
+			name:    "external link",
+			page:    "library/functions",
+			content: `<a class="reference external" href="https://peps.python.org/pep-0578/">PEP 578</a>`,
+			want:    `<a class="reference external" href="https://peps.python.org/pep-0578/">PEP 578</a>`,
+		},
+		{
+			name:    "img src",
+			page:    "library/functions",
+			content: `<img alt="../_images/pathlib-inheritance.png" src="../_images/pathlib-inheritance.png">`,
+			want:    `<img alt="../_images/pathlib-inheritance.png" src="../_images/pathlib-inheritance.png"/>`,
+		},
+		{
+			name:    "img srcset",
+			page:    "library/functions",
+			content: `<img src="_images/logo.png" srcset="_images/logo.png 1x, _images/logo@2x.png 2x">`,
+			want:    `<img src="_images/logo.png" srcset="_images/logo.png 1x, _images/logo@2x.png 2x"/>`,
+		},
+		{
+			name:    "remote img without fetcher",
+			page:    "library/functions",
+			content: `<img src="https://mdn.mozillademos.org/files/3437/Callback.png" alt="callback">`,
+			want:    `<img src="https://mdn.mozillademos.org/files/3437/Callback.png" alt="callback"/>`,
+		},
+		{
+			name:    "remote iframe",
+			page:    "library/functions",
+			content: `<iframe class="interactive" height="200" src="https://interactive-examples.mdn.mozilla.net/pages/js/array-join.html" title="MDN Web Docs Interactive Example"></iframe>`,
+			want:    `<iframe class="interactive" height="200" src="https://interactive-examples.mdn.mozilla.net/pages/js/array-join.html" title="MDN Web Docs Interactive Example"></iframe>`,
+		},
+		{
+			name:    "href-like text in pre",
+			page:    "library/functions",
+			content: `<pre data-language="html"><span class="tag">&lt;a</span> <span class="attr">href</span>=<span class="value">"stdtypes#str"</span><span class="tag">&gt;</span></pre>`,
+			want:    `<pre data-language="html"><span class="tag">&lt;a</span> <span class="attr">href</span>=<span class="value">&#34;stdtypes#str&#34;</span><span class="tag">&gt;</span></pre>`,
+		},
+		{
+			name:    "href-like text in code",
+			page:    "library/functions",
+			content: `<p>Write <code>href="os.path"</code> to link.</p>`,
+			want:    `<p>Write <code>href=&#34;os.path&#34;</code> to link.</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.rewriteLinks("python", tt.page, tt.content, links)
+			if err != nil {
+				t.Fatalf("rewriteLinks() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("rewriteLinks() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lookupEntry resolves a symbol to the single best entry across the given
+// docsets, or all installed docsets if none are given. Exact name matches
+// win over case-insensitive ones, which win over fuzzy matches. A running
+// daemon answers instead when there is one.
+func lookupEntry(cache *Cache, symbol string, docsets ...string) (searchResult, error) {
+	if resp, ok := callDaemon(cache, daemonRequest{
+		Method:  "lookup",
+		Symbol:  symbol,
+		Docsets: docsets,
+	}); ok {
+		if err := resp.err(); err != nil {
+			return searchResult{}, err
+		}
+		if results := resp.searchResults(); len(results) > 0 {
+			return results[0], nil
+		}
+		return searchResult{}, fmt.Errorf("no entry found for %s", symbol)
+	}
+
+	return newResolver(cache).lookup(symbol, docsets...)
+}
+
+// resolver looks up symbols, loading the index of each docset only once
+type resolver struct {
+	cache     *Cache
+	ranker    *ranker
+	installed []string
+	indexes   map[string][]DocumentEntry
+}
+
+func newResolver(cache *Cache) *resolver {
+	return &resolver{
+		cache:   cache,
+		ranker:  newRanker(cache, nil),
+		indexes: make(map[string][]DocumentEntry),
+	}
+}
+
+func (s *resolver) entries(slug string) ([]DocumentEntry, error) {
+	if entries, ok := s.indexes[slug]; ok {
+		return entries, nil
+	}
+	entries, err := loadEntries(s.cache, slug)
+	if err != nil {
+		return nil, fmt.Errorf("documentation %s is not installed: %w", slug, err)
+	}
+	s.indexes[slug] = entries
+	return entries, nil
+}
+
+// lookup resolves a symbol like lookupEntry, in-process
+func (s *resolver) lookup(symbol string, docsets ...string) (searchResult, error) {
+	r := s.ranker.withOrder(docsets)
+
+	// Without specific docsets, look in all installed documentations and
+	// skip the ones that fail to load
+	explicit := len(docsets) > 0
+	if !explicit {
+		if s.installed == nil {
+			slugs, err := installedDocsets(s.cache)
+			if err != nil {
+				return searchResult{}, err
+			}
+			s.installed = slugs
+		}
+		docsets = s.installed
+	}
+
+	indexes := make(map[string][]DocumentEntry, len(docsets))
+	for _, slug := range docsets {
+		entries, err := s.entries(slug)
+		if err != nil {
+			if explicit {
+				return searchResult{}, err
+			}
+			continue
+		}
+		indexes[slug] = entries
+	}
+
+	for _, match := range []func(string) bool{
+		func(name string) bool { return name == symbol },
+		func(name string) bool { return strings.EqualFold(name, symbol) },
+	} {
+		for _, slug := range docsets {
+			for _, entry := range indexes[slug] {
+				if match(entry.Name) {
+					return searchResult{
+						docset: slug,
+						entry:  entry,
+						score:  r.score(slug, entry, symbol, 0),
+					}, nil
+				}
+			}
+		}
+	}
+
+	var best searchResult
+	found := false
+	for _, slug := range docsets {
+		results := matchEntries(slug, indexes[slug], searchQuery{text: symbol}, r, 1)
+		if len(results) > 0 && (!found || results[0].score > best.score) {
+			best = results[0]
+			found = true
+		}
+	}
+	if !found {
+		return searchResult{}, fmt.Errorf("no entry found for %s", symbol)
+	}
+	return best, nil
+}
+
+// resolution is a line of ddc resolve output
+type resolution struct {
+	Symbol string         `json:"symbol"`
+	Docset string         `json:"docset,omitempty"`
+	Status string         `json:"status"` // found or not_found
+	Entry  *DocumentEntry `json:"entry,omitempty"`
+	Score  int            `json:"score,omitempty"`
+	Path   string         `json:"path,omitempty"` // Local HTML file and fragment
+	URL    string         `json:"url,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// resolve looks up a line of input, either docset<TAB>symbol or a bare
+// symbol looked up in all installed docsets
+func (s *resolver) resolve(line string) resolution {
+	var res resolution
+	var docsets []string
+	if docset, symbol, ok := strings.Cut(line, "\t"); ok {
+		res.Docset, res.Symbol = strings.TrimSpace(docset), strings.TrimSpace(symbol)
+		docsets = []string{res.Docset}
+	} else {
+		res.Symbol = strings.TrimSpace(line)
+	}
+
+	result, err := s.lookup(res.Symbol, docsets...)
+	if err != nil {
+		res.Status = "not_found"
+		res.Error = err.Error()
+		return res
+	}
+
+	htmlPath, fragment := s.cache.GetHTMLPath(result.docset, result.entry.Path)
+	res.Status = "found"
+	res.Docset = result.docset
+	res.Entry = &result.entry
+	res.Score = result.score
+	res.Path = htmlPath + fragment
+	res.URL = entryURL(s.cache, result.docset, result.entry)
+	return res
+}
+
+// entryURL returns the devdocs.io URL of an entry
+func entryURL(cache *Cache, slug string, entry DocumentEntry) string {
+	devdocsSlug := slug
+	if meta, err := cache.GetMeta(slug); err == nil && meta.Slug != "" {
+		devdocsSlug = meta.Slug
+	}
+	return devdocsURL + devdocsSlug + "/" + entry.Path
+}
+
+// renderEntry renders the section of a page an entry points at as plain
+// text or markdown
+func renderEntry(client *DevDoc, slug string, entry DocumentEntry, markdown bool) (string, error) {
+	base, fragment := entry.SplitFragment()
+	content, err := client.GetDocument(slug, base)
+	if err != nil {
+		return "", err
+	}
+
+	if markdown {
+		return htmlToMarkdown(content, fragment)
+	}
+	return htmlToText(content, fragment)
+}
@@ -1,23 +1,34 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/charmbracelet/bubbletea/v2"
 	"github.com/urfave/cli/v3"
 )
 
-// runView starts a TUI to view documentation entries for a given slug
+// runView starts a TUI to view documentation entries for a given slug. A
+// slug that isn't installed goes through resolveDocset first.
 func runView(slug string) error {
 	cache := newCache()
 	client := newDocs(cache)
 
-	if !client.IsDocSetInstalled(slug) {
+	resolved, ok, err := resolveDocset(cache, client, slug, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
 		return cli.Exit(fmt.Sprintf("Documentation %s is not installed. Use 'ddc download %s' first", slug, slug), 1)
 	}
+	slug = resolved
 
 	docsets, err := client.GetDocumentation(slug)
 	if err != nil {
@@ -31,11 +42,32 @@ func runView(slug string) error {
 	return err
 }
 
-// runSearch starts a TUI to search with an optional docset filter
-func runSearch(query string, docset ...string) error {
+// searchOptions are the options of the search TUI
+type searchOptions struct {
+	global      bool // Ignore the .ddc project file
+	group       bool // Start with results grouped by docset
+	allVersions bool // Search every installed version of the docsets
+	limit       int  // Results shown until more are loaded, all if not positive
+}
+
+// defaultSearchOptions are the options of searches started without flags
+var defaultSearchOptions = searchOptions{limit: searchPageSize}
+
+// runSearch starts a TUI to search with an optional docset filter. Without
+// one, the search is scoped to the docsets of the current project unless
+// global is set.
+func runSearch(query string, opts searchOptions, docset ...string) error {
 	cache := newCache()
 
-	model, err := NewSearchModel(cache, query, docset...)
+	if len(docset) == 0 && !opts.global {
+		slugs, err := projectDocsets(cache)
+		if err != nil {
+			return err
+		}
+		docset = slugs
+	}
+
+	model, err := NewSearchModel(cache, query, opts, docset...)
 	if err != nil {
 		return err
 	}
@@ -50,18 +82,23 @@ func runDownload(docs string) error {
 	cache := newCache()
 	client := newDocs(cache)
 
-	docsets, err := ListDocumentations()
+	docsets, err := ListDocumentations(cache)
 	if err != nil {
 		return err
 	}
 
 	if docs != "" {
-		// Filter docsets by provided slugs
+		// Find the docset by its slug. Other versions of an installed family
+		// go next to it.
 		for _, doc := range docsets {
-			if doc.Slug == docs {
-				if !client.IsDocSetInstalled(docs) {
-					client.DownloadDocSet(&doc)
+			for _, version := range doc.ListVersions() {
+				if version.Slug != docs {
+					continue
+				}
+				if dir := versionDir(cache, version); !client.IsDocSetInstalled(dir) {
+					return client.DownloadDocSetTo(&version, dir)
 				}
+				return nil
 			}
 		}
 		return nil
@@ -86,10 +123,333 @@ func runList() error {
 	return err
 }
 
+// runBookmarks starts a TUI to browse and open bookmarks
+func runBookmarks() error {
+	cache := newCache()
+
+	bookmarks, err := cache.GetBookmarks()
+	if err != nil {
+		return err
+	}
+
+	model := NewBookmarkModel(cache, bookmarks)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	_, err = p.Run()
+	return err
+}
+
+// runRecent starts a TUI to reopen recently viewed entries
+func runRecent() error {
+	cache := newCache()
+
+	visits, err := cache.RecentVisits()
+	if err != nil {
+		return err
+	}
+
+	model := NewRecentModel(cache, visits)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	_, err = p.Run()
+	return err
+}
+
+// runServe serves installed documentation over HTTP
+func runServe(addr string) error {
+	server := NewServer(newCache())
+
+	fmt.Printf("Serving documentation on http://%s\n", addr)
+	return http.ListenAndServe(addr, server.Handler())
+}
+
+// runDaemon keeps installed documentation in memory and answers searches
+// and lookups of other ddc processes until interrupted
+func runDaemon() error {
+	cache := newCache()
+	daemon := NewDaemon(cache)
+
+	fmt.Printf("Listening on %s\n", cache.daemonSocket())
+	return daemon.Serve()
+}
+
+// runGrep prints the lines of the given docsets' pages matching a regular
+// expression. Without docsets, the ones of the current project are searched
+// unless global is set, then all installed ones.
+func runGrep(pattern string, ignoreCase, global bool, opts grepOptions, docsets ...string) error {
+	cache := newCache()
+
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("Invalid pattern: %v", err), 2)
+	}
+
+	for _, slug := range docsets {
+		if !cache.DocsetExists(slug) {
+			return cli.Exit(fmt.Sprintf("Documentation %s is not installed. Use 'ddc download %s' first", slug, slug), 2)
+		}
+	}
+	if len(docsets) == 0 && !global {
+		if docsets, err = projectDocsets(cache); err != nil {
+			return err
+		}
+	}
+	if len(docsets) == 0 {
+		if docsets, err = installedDocsets(cache); err != nil {
+			return err
+		}
+	}
+
+	matched, err := grepDocsets(cache, re, opts, docsets, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// runResolve resolves symbols to entries and prints a JSON line for each.
+// With batch, lines of docset<TAB>symbol or bare symbols are read from stdin.
+func runResolve(batch bool, symbols []string) error {
+	cache := newCache()
+	r := newResolver(cache)
+	enc := json.NewEncoder(os.Stdout)
+
+	if !batch {
+		for _, symbol := range symbols {
+			if err := enc.Encode(r.resolve(symbol)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := enc.Encode(r.resolve(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// runLookup prints the documentation of the entry best matching a symbol
+func runLookup(symbol string, format string, docsets ...string) error {
+	cache := newCache()
+	client := newDocs(cache)
+
+	for _, slug := range docsets {
+		if !client.IsDocSetInstalled(slug) {
+			return cli.Exit(fmt.Sprintf("Documentation %s is not installed. Use 'ddc download %s' first", slug, slug), 1)
+		}
+	}
+
+	result, err := lookupEntry(cache, symbol, docsets...)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	switch format {
+	case "path":
+		htmlPath, fragment := cache.GetHTMLPath(result.docset, result.entry.Path)
+		fmt.Println(htmlPath + fragment)
+		return nil
+	case "url":
+		fmt.Println(entryURL(cache, result.docset, result.entry))
+		return nil
+	}
+
+	text, err := renderEntry(client, result.docset, result.entry, format == "markdown")
+	if err != nil {
+		return err
+	}
+	fmt.Println(text)
+	return nil
+}
+
+// runDetect reports docsets matching the dependencies of the project in the
+// working directory and offers to install the missing ones
+func runDetect(yes bool) error {
+	cache := newCache()
+	client := newDocs(cache)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	deps := detectDependencies(dir)
+	if len(deps) == 0 {
+		fmt.Println("No go.mod, package.json, composer.json, requirements.txt, pyproject.toml or Gemfile found")
+		return nil
+	}
+
+	catalog, err := ListDocumentations(cache)
+	if err != nil {
+		return err
+	}
+
+	// Match dependencies to catalog families, preferring the declaration
+	// that pins a version when several manifests mention the same one
+	var families []Documentation
+	versions := make(map[string]projectDependency)
+	for _, dep := range deps {
+		doc, ok := findCatalogDoc(catalog, dep)
+		if !ok {
+			continue
+		}
+		existing, seen := versions[doc.Kind()]
+		if !seen {
+			families = append(families, doc)
+		}
+		if !seen || (existing.Version == "" && dep.Version != "") {
+			versions[doc.Kind()] = dep
+		}
+	}
+
+	// A pinned version that isn't installed goes next to the installed one,
+	// like ddc sync does
+	var install []Documentation
+	for _, doc := range families {
+		dep := versions[doc.Kind()]
+		want := closestVersion(doc, dep.Version)
+		dir := versionDir(cache, want)
+
+		status := "missing"
+		switch meta, err := cache.GetMeta(dir); {
+		case !client.IsDocSetInstalled(want.Kind()):
+			install = append(install, want)
+		case !dep.pinned() || want.Version == "":
+			status = "installed"
+		case err == nil && (meta.Slug == want.Slug || meta.Version == want.Version):
+			status = "installed"
+		default:
+			installed, _ := cache.GetMeta(want.Kind())
+			status = fmt.Sprintf("installed %s, project uses %s", installed.Version, want.Version)
+			install = append(install, want)
+		}
+
+		fmt.Printf("%-25s %-40s %s\n", want.GetDisplayName(), status, dep.Source)
+	}
+
+	if len(install) == 0 {
+		return nil
+	}
+	if !yes && !confirm(fmt.Sprintf("Install %d documentation sets?", len(install))) {
+		return nil
+	}
+
+	for _, doc := range install {
+		fmt.Printf("Downloading %s...\n", doc.GetDisplayName())
+		if err := client.DownloadDocSetTo(&doc, versionDir(cache, doc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSync installs the docsets listed in the project file, in the versions
+// it asks for
+func runSync() error {
+	cache := newCache()
+	client := newDocs(cache)
+
+	project, err := currentProject()
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return cli.Exit(fmt.Sprintf("No %s file found in this directory or its parents", projectFileName), 1)
+	}
+
+	catalog, err := ListDocumentations(cache)
+	if err != nil {
+		return err
+	}
+
+	for _, docset := range project.Docsets {
+		doc, ok := findCatalogDoc(catalog, projectDependency{Name: docset.Name, Version: docset.Version})
+		if !ok {
+			fmt.Printf("%-25s not found in the DevDocs catalog\n", docset.Name)
+			continue
+		}
+
+		// A pinned version that differs from the installed one goes next to
+		// it, like ddc download does
+		want := closestVersion(doc, docset.Version)
+		dir := versionDir(cache, want)
+		if docset.Version == "" && client.IsDocSetInstalled(want.Kind()) {
+			dir = want.Kind()
+		}
+		if client.IsDocSetInstalled(dir) {
+			meta, err := cache.GetMeta(dir)
+			if err == nil && (docset.Version == "" || meta.Version == want.Version) {
+				fmt.Printf("%-25s up to date\n", want.GetDisplayName())
+				continue
+			}
+		}
+
+		fmt.Printf("%-25s downloading...\n", want.GetDisplayName())
+		if err := client.DownloadDocSetTo(&want, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// findEntry looks up an entry of a docset by path or name
+func findEntry(client *DevDoc, slug string, nameOrPath string) (DocumentEntry, error) {
+	entries, err := client.GetDocumentation(slug)
+	if err != nil {
+		return DocumentEntry{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.Path == nameOrPath {
+			return entry, nil
+		}
+	}
+	for _, entry := range entries {
+		if entry.Name == nameOrPath {
+			return entry, nil
+		}
+	}
+	return DocumentEntry{}, fmt.Errorf("entry %s not found in %s", nameOrPath, slug)
+}
+
+// globalFlag makes searches ignore the docsets listed in a .ddc project file
+var globalFlag = &cli.BoolFlag{
+	Name:    "global",
+	Aliases: []string{"g"},
+	Usage:   "Search all installed documentation sets, ignoring the .ddc project file",
+}
+
 var rootCmd = &cli.Command{
 	EnableShellCompletion: true,
 	Name:                  "ddc",
 	Usage:                 "DevDocs CLI browser",
+	Flags: []cli.Flag{
+		globalFlag,
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		// Implement the smart command logic here
 		args := cmd.Args().Slice()
@@ -103,37 +463,36 @@ var rootCmd = &cli.Command{
 			// One argument - could be a documentation set or a search term
 			firstArg := cmd.Args().First()
 
-			// Check if it's an installed documentation set
-			if client.IsDocSetInstalled(firstArg) {
+			// Check if it's an installed documentation set, or one to install.
+			// Misspellings aren't suggested, most single words are searches.
+			slug, ok, err := resolveDocset(cache, client, firstArg, false)
+			if err != nil {
+				return err
+			}
+			if ok {
 				// It's a doc set, view it
-				return runView(firstArg)
-			} else {
-				// Not a doc set, treat as search query across all docs
-				return runSearch(firstArg)
+				return runView(slug)
 			}
+
+			// Not a doc set, treat as search query across all docs
+			opts := defaultSearchOptions
+			opts.global = cmd.Bool("global")
+			return runSearch(firstArg, opts)
 		default:
 			// Multiple arguments - first arg is the doc set, rest is the search query
-			docSet := args[0]
-			searchQuery := args[1]
-
-			// For multiple search terms, join them
-			for i := 2; i < cmd.Args().Len(); i++ {
-				searchQuery += " " + args[i]
+			slug, ok, err := resolveDocset(cache, client, args[0], true)
+			if err != nil {
+				return err
 			}
-
-			// If the doc set exists, search within it
-			if client.IsDocSetInstalled(docSet) {
+			if ok {
 				// Search within the specified doc set
-				return runSearch(searchQuery, docSet)
-			} else {
-				// If doc set doesn't exist, treat all args as a search query
-				fullQuery := args[0]
-				for i := 1; i < cmd.Args().Len(); i++ {
-					fullQuery += " " + args[i]
-				}
-
-				return runSearch(fullQuery)
+				return runSearch(strings.Join(args[1:], " "), defaultSearchOptions, slug)
 			}
+
+			// If doc set doesn't exist, treat all args as a search query
+			opts := defaultSearchOptions
+			opts.global = cmd.Bool("global")
+			return runSearch(strings.Join(args, " "), opts)
 		}
 	},
 	Commands: []*cli.Command{
@@ -141,12 +500,34 @@ var rootCmd = &cli.Command{
 			Name:    "search",
 			Aliases: []string{"s"},
 			Usage:   "Search across all installed documentation sets",
+			Flags: []cli.Flag{
+				globalFlag,
+				&cli.BoolFlag{
+					Name:  "group",
+					Usage: "Group results by documentation set",
+				},
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"n"},
+					Usage:   "Number of results to show before loading more, 0 for all",
+					Value:   searchPageSize,
+				},
+				&cli.BoolFlag{
+					Name:  "all-versions",
+					Usage: "Search every installed version of the documentation sets",
+				},
+			},
 			Action: func(ctx context.Context, cmd *cli.Command) error {
-				if cmd.Args().Len() != 1 {
+				if cmd.Args().Len() == 0 {
 					return cli.Exit("Please provide a search query", 1)
 				}
-				query := cmd.Args().First()
-				return runSearch(query)
+				query := strings.Join(cmd.Args().Slice(), " ")
+				return runSearch(query, searchOptions{
+					global:      cmd.Bool("global"),
+					group:       cmd.Bool("group"),
+					allVersions: cmd.Bool("all-versions"),
+					limit:       int(cmd.Int("limit")),
+				})
 			},
 		},
 		{
@@ -177,6 +558,297 @@ var rootCmd = &cli.Command{
 				return runList()
 			},
 		},
+		{
+			Name:      "lookup",
+			Aliases:   []string{"l"},
+			Usage:     "Print the documentation of a symbol without the TUI",
+			ArgsUsage: "[docset] <symbol> | --file <file> --word <symbol>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "markdown",
+					Aliases: []string{"m"},
+					Usage:   "Print markdown instead of plain text",
+				},
+				&cli.BoolFlag{
+					Name:  "path",
+					Usage: "Only print the path of the local HTML file",
+				},
+				&cli.BoolFlag{
+					Name:  "url",
+					Usage: "Only print the devdocs.io URL",
+				},
+				&cli.StringFlag{
+					Name:    "file",
+					Aliases: []string{"f"},
+					Usage:   "Pick docsets by the extension or shebang of this file",
+				},
+				&cli.StringFlag{
+					Name:    "word",
+					Aliases: []string{"w"},
+					Usage:   "Symbol to look up, instead of passing it as an argument",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				format := "text"
+				switch {
+				case cmd.Bool("path"):
+					format = "path"
+				case cmd.Bool("url"):
+					format = "url"
+				case cmd.Bool("markdown"):
+					format = "markdown"
+				}
+
+				args := cmd.Args().Slice()
+				if word := cmd.String("word"); word != "" {
+					args = append(args, word)
+				}
+
+				switch len(args) {
+				case 1:
+					var docsets []string
+					if file := cmd.String("file"); file != "" {
+						var err error
+						if docsets, err = languageDocsets(newCache(), file); err != nil {
+							return err
+						}
+					}
+					return runLookup(args[0], format, docsets...)
+				case 2:
+					return runLookup(args[1], format, args[0])
+				}
+				return cli.Exit("Please provide a symbol, optionally preceded by a documentation name", 1)
+			},
+		},
+		{
+			Name:  "detect",
+			Usage: "Suggest documentation sets for the dependencies of the current project",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Install missing documentation sets without asking",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return runDetect(cmd.Bool("yes"))
+			},
+		},
+		{
+			Name:  "sync",
+			Usage: "Install the documentation sets listed in the .ddc project file",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return runSync()
+			},
+		},
+		{
+			Name:  "serve",
+			Usage: "Serve installed documentation over HTTP",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "addr",
+					Value: "127.0.0.1:8080",
+					Usage: "Address to listen on",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return runServe(cmd.String("addr"))
+			},
+		},
+		{
+			Name:      "resolve",
+			Usage:     "Resolve symbols to documentation entries as JSON lines",
+			ArgsUsage: "<symbol...> | --batch < symbols",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "batch",
+					Usage: "Read docset<TAB>symbol or bare symbols from stdin, one per line",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if !cmd.Bool("batch") && cmd.Args().Len() == 0 {
+					return cli.Exit("Please provide symbols or --batch", 1)
+				}
+				return runResolve(cmd.Bool("batch"), cmd.Args().Slice())
+			},
+		},
+		{
+			Name:      "grep",
+			Usage:     "Search the text of documentation pages with a regular expression",
+			ArgsUsage: "<pattern> [docset...]",
+			Flags: []cli.Flag{
+				globalFlag,
+				&cli.BoolFlag{
+					Name:    "ignore-case",
+					Aliases: []string{"i"},
+					Usage:   "Match case-insensitively",
+				},
+				&cli.IntFlag{
+					Name:    "context",
+					Aliases: []string{"C"},
+					Usage:   "Lines of context around matches",
+				},
+				&cli.IntFlag{
+					Name:    "before-context",
+					Aliases: []string{"B"},
+					Usage:   "Lines of context before matches",
+				},
+				&cli.IntFlag{
+					Name:    "after-context",
+					Aliases: []string{"A"},
+					Usage:   "Lines of context after matches",
+				},
+				&cli.BoolFlag{
+					Name:  "vimgrep",
+					Usage: "Print file:line:col of matches in the HTML files, for vim's quickfix list",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() == 0 {
+					return cli.Exit("Please provide a pattern", 2)
+				}
+
+				opts := grepOptions{
+					before:  int(cmd.Int("context")),
+					after:   int(cmd.Int("context")),
+					vimgrep: cmd.Bool("vimgrep"),
+				}
+				if cmd.IsSet("before-context") {
+					opts.before = int(cmd.Int("before-context"))
+				}
+				if cmd.IsSet("after-context") {
+					opts.after = int(cmd.Int("after-context"))
+				}
+
+				args := cmd.Args().Slice()
+				return runGrep(args[0], cmd.Bool("ignore-case"), cmd.Bool("global"), opts, args[1:]...)
+			},
+		},
+		{
+			Name:  "daemon",
+			Usage: "Keep documentation indexes in memory to answer searches and lookups instantly",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return runDaemon()
+			},
+		},
+		{
+			Name:  "mcp",
+			Usage: "Serve installed documentation to AI assistants over the Model Context Protocol (stdio)",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				cache := newCache()
+				server := NewMCPServer(cache, newDocs(cache))
+				return server.Serve(os.Stdin, os.Stdout)
+			},
+		},
+		{
+			Name:  "recent",
+			Usage: "Reopen recently viewed entries",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return runRecent()
+			},
+			Commands: []*cli.Command{
+				{
+					Name:  "clear",
+					Usage: "Forget all viewed entries",
+					Action: func(ctx context.Context, cmd *cli.Command) error {
+						return newCache().ClearHistory()
+					},
+				},
+			},
+		},
+		{
+			Name:    "bookmark",
+			Aliases: []string{"bm"},
+			Usage:   "Browse and manage bookmarked entries",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return runBookmarks()
+			},
+			Commands: []*cli.Command{
+				{
+					Name:      "add",
+					Usage:     "Bookmark an entry of an installed documentation set",
+					ArgsUsage: "<docset> <entry>",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "tag",
+							Aliases: []string{"t"},
+							Usage:   "Tag to group the bookmark under",
+						},
+					},
+					Action: func(ctx context.Context, cmd *cli.Command) error {
+						if cmd.Args().Len() != 2 {
+							return cli.Exit("Please provide a documentation set and an entry name or path", 1)
+						}
+						cache := newCache()
+						client := newDocs(cache)
+
+						slug := cmd.Args().Get(0)
+						if !client.IsDocSetInstalled(slug) {
+							return cli.Exit(fmt.Sprintf("Documentation %s is not installed. Use 'ddc download %s' first", slug, slug), 1)
+						}
+
+						entry, err := findEntry(client, slug, cmd.Args().Get(1))
+						if err != nil {
+							return cli.Exit(err.Error(), 1)
+						}
+						if err := cache.AddBookmark(slug, entry, cmd.String("tag")); err != nil {
+							return err
+						}
+						fmt.Printf("Bookmarked %s: %s\n", slug, entry.Name)
+						return nil
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "Print bookmarked entries",
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "tag",
+							Aliases: []string{"t"},
+							Usage:   "Only print bookmarks with this tag",
+						},
+					},
+					Action: func(ctx context.Context, cmd *cli.Command) error {
+						bookmarks, err := newCache().GetBookmarks()
+						if err != nil {
+							return err
+						}
+						tag := cmd.String("tag")
+						for _, b := range bookmarks {
+							if tag != "" && b.Tag != tag {
+								continue
+							}
+							line := fmt.Sprintf("%s\t%s\t%s\t%s", b.Docset, b.Name, b.Path, b.Tag)
+							if b.Missing {
+								line += "\tmissing"
+							}
+							fmt.Println(line)
+						}
+						return nil
+					},
+				},
+				{
+					Name:      "rm",
+					Aliases:   []string{"remove"},
+					Usage:     "Remove a bookmark",
+					ArgsUsage: "<docset> <entry>",
+					Action: func(ctx context.Context, cmd *cli.Command) error {
+						if cmd.Args().Len() != 2 {
+							return cli.Exit("Please provide a documentation set and an entry name or path", 1)
+						}
+						removed, err := newCache().RemoveBookmark(cmd.Args().Get(0), cmd.Args().Get(1))
+						if err != nil {
+							return err
+						}
+						if !removed {
+							return cli.Exit(fmt.Sprintf("No bookmark %s in %s", cmd.Args().Get(1), cmd.Args().Get(0)), 1)
+						}
+						return nil
+					},
+				},
+			},
+		},
 	},
 }
 
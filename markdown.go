@@ -0,0 +1,406 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// textRenderer converts documentation HTML to markdown or plain text
+type textRenderer struct {
+	markdown bool
+	blocks   []string // Preformatted blocks, referenced by placeholders
+}
+
+// htmlToMarkdown converts a documentation page to markdown. With a fragment
+// only the section it points at is converted.
+func htmlToMarkdown(content string, fragment string) (string, error) {
+	return renderHTML(content, fragment, true)
+}
+
+// htmlToText converts a documentation page to plain text. With a fragment
+// only the section it points at is converted.
+func htmlToText(content string, fragment string) (string, error) {
+	return renderHTML(content, fragment, false)
+}
+
+func renderHTML(content string, fragment string, markdown bool) (string, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return "", err
+	}
+
+	// Parsed nodes come back detached, link them up so sections can be
+	// collected from siblings
+	for _, n := range nodes {
+		context.AppendChild(n)
+	}
+
+	if id := strings.TrimPrefix(fragment, "#"); id != "" {
+		if section := findSection(context, id); len(section) > 0 {
+			nodes = section
+		}
+	}
+
+	r := &textRenderer{markdown: markdown}
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(r.render(n))
+	}
+	return r.finish(b.String()), nil
+}
+
+// findSection returns the nodes that make up the section with the given id:
+// a heading and everything up to the next heading of the same level, a
+// definition term and its descriptions, or the element itself.
+func findSection(root *html.Node, id string) []*html.Node {
+	target := findByID(root, id)
+	if target == nil {
+		return nil
+	}
+
+	// Anchors inside headings and terms stand for their parent
+	for target.Parent != nil && target.Parent != root && isInline(target) {
+		target = target.Parent
+	}
+
+	section := []*html.Node{target}
+	switch {
+	case headingLevel(target) > 0:
+		level := headingLevel(target)
+		for sib := target.NextSibling; sib != nil; sib = sib.NextSibling {
+			if l := headingLevel(sib); l > 0 && l <= level {
+				break
+			}
+			section = append(section, sib)
+		}
+	case target.DataAtom == atom.Dt:
+		for sib := target.NextSibling; sib != nil; sib = sib.NextSibling {
+			if sib.DataAtom == atom.Dt {
+				break
+			}
+			section = append(section, sib)
+		}
+	}
+	return section
+}
+
+func findByID(n *html.Node, id string) *html.Node {
+	if n.Type == html.ElementNode {
+		for _, attr := range n.Attr {
+			if (attr.Key == "id" || (attr.Key == "name" && n.DataAtom == atom.A)) && attr.Val == id {
+				return n
+			}
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findByID(child, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func headingLevel(n *html.Node) int {
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+func isInline(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.A, atom.Span, atom.Code, atom.Strong, atom.B, atom.Em, atom.I, atom.Var:
+		return true
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the raw text of a node and its children
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// codeLanguage guesses the language of a code sample from its attributes
+func codeLanguage(n *html.Node) string {
+	for _, node := range []*html.Node{n, n.FirstChild} {
+		if node == nil || node.Type != html.ElementNode {
+			continue
+		}
+		if lang := attr(node, "data-language"); lang != "" {
+			return lang
+		}
+		for _, class := range strings.Fields(attr(node, "class")) {
+			if lang, ok := strings.CutPrefix(class, "language-"); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// block stores preformatted text and returns a placeholder for it, so
+// whitespace cleanup of the surrounding text leaves it untouched
+func (r *textRenderer) block(text string) string {
+	r.blocks = append(r.blocks, text)
+	return "\n\n\x00" + strconv.Itoa(len(r.blocks)-1) + "\x00\n\n"
+}
+
+// finish cleans up whitespace and expands placeholders
+func (r *textRenderer) finish(text string) string {
+	var lines []string
+	blank := true
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+
+	result := strings.TrimSpace(strings.Join(lines, "\n"))
+	for i := len(r.blocks) - 1; i >= 0; i-- {
+		result = strings.ReplaceAll(result, "\x00"+strconv.Itoa(i)+"\x00", r.blocks[i])
+	}
+	return result
+}
+
+func (r *textRenderer) children(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(r.render(child))
+	}
+	return b.String()
+}
+
+// inline renders the children of a node on a single line
+func (r *textRenderer) inline(n *html.Node) string {
+	return strings.Join(strings.Fields(r.children(n)), " ")
+}
+
+func (r *textRenderer) render(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return collapseSpace(n.Data)
+	case html.ElementNode:
+	default:
+		return r.children(n)
+	}
+
+	if level := headingLevel(n); level > 0 {
+		text := r.inline(n)
+		if r.markdown {
+			text = strings.Repeat("#", level) + " " + text
+		}
+		return "\n\n" + text + "\n\n"
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Template:
+		return ""
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		if r.markdown {
+			return "\n\n---\n\n"
+		}
+		return "\n\n"
+	case atom.Pre:
+		code := strings.TrimRight(textContent(n), "\n")
+		if r.markdown {
+			return r.block("```" + codeLanguage(n) + "\n" + code + "\n```")
+		}
+		return r.block(code)
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		if r.markdown {
+			return "`" + strings.TrimSpace(textContent(n)) + "`"
+		}
+		return textContent(n)
+	case atom.Strong, atom.B:
+		if r.markdown {
+			return "**" + r.inline(n) + "**"
+		}
+	case atom.Em, atom.I, atom.Var:
+		if r.markdown {
+			return "*" + r.inline(n) + "*"
+		}
+	case atom.A:
+		href := attr(n, "href")
+		if r.markdown && isRemoteURL(href) {
+			return "[" + r.inline(n) + "](" + href + ")"
+		}
+	case atom.Img:
+		if alt := attr(n, "alt"); alt != "" {
+			if r.markdown {
+				return "![" + alt + "]"
+			}
+			return "[image: " + alt + "]"
+		}
+		return ""
+	case atom.Ul, atom.Ol:
+		return r.list(n)
+	case atom.Dt:
+		text := strings.Join(strings.Fields(textContent(n)), " ")
+		if r.markdown {
+			text = "**" + text + "**"
+		}
+		return "\n\n" + text + "\n"
+	case atom.Dd:
+		return r.block(indent(r.finish(r.children(n)), "    "))
+	case atom.Table:
+		return r.table(n)
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.Aside, atom.Figure, atom.Figcaption, atom.Dl, atom.Details, atom.Summary:
+		return "\n\n" + r.children(n) + "\n\n"
+	case atom.Blockquote:
+		text := r.finish(r.children(n))
+		if r.markdown {
+			return r.block(indent(text, "> "))
+		}
+		return r.block(indent(text, "  "))
+	}
+
+	return r.children(n)
+}
+
+func (r *textRenderer) list(n *html.Node) string {
+	var items []string
+	number := 1
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		text := r.finish(r.children(li))
+		pad := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(indent(text, pad), pad))
+	}
+	return r.block(strings.Join(items, "\n"))
+}
+
+func (r *textRenderer) table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.DataAtom == atom.Tr {
+			var cells []string
+			for cell := node.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+					cells = append(cells, strings.ReplaceAll(r.finish(r.children(cell)), "\n", " "))
+				}
+			}
+			rows = append(rows, cells)
+			return
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, cells := range rows {
+		if r.markdown {
+			lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 {
+				lines = append(lines, "|"+strings.Repeat(" --- |", len(cells)))
+			}
+		} else {
+			lines = append(lines, strings.Join(cells, " | "))
+		}
+	}
+	return r.block(strings.Join(lines, "\n"))
+}
+
+// collapseSpace replaces runs of whitespace with a single space
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, c := range s {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' {
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		b.WriteRune(c)
+		space = false
+	}
+	return b.String()
+}
+
+// indent prefixes every non-empty line of text
+func indent(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateText cuts text down to at most max bytes on a line boundary
+func truncateText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+
+	cut := text[:max]
+	if i := strings.LastIndex(cut, "\n"); i > max/2 {
+		cut = cut[:i]
+	}
+	return cut + fmt.Sprintf("\n\n[truncated, %d more bytes]", len(text)-len(cut))
+}
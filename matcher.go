@@ -0,0 +1,197 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scores of the name matcher
+const (
+	matchCharScore        = 16
+	matchStartBonus       = 10 // First character of the name
+	matchSeparatorBonus   = 9  // Character after . :: -> _ and friends
+	matchHumpBonus        = 8  // camelCase hump or start of a number
+	matchConsecutiveBonus = 4
+	matchExactCaseBonus   = 1
+	matchGapPenalty       = 1 // Per skipped character
+)
+
+// match is the result of matching a query against a name
+type match struct {
+	score     int
+	positions []int // Indexes of matched runes in the name
+}
+
+// nameMatcher matches a query against names. The query is split on spaces
+// into tokens that must all match, in any order. Each token matches as a
+// subsequence, preferring the starts of camelCase humps and of segments
+// separated by . :: -> _ and similar, so "gebi" finds getElementById and
+// "ospj" finds os.path.join.
+type nameMatcher struct {
+	tokens      [][]rune
+	lowerTokens [][]rune
+}
+
+func newNameMatcher(query string) *nameMatcher {
+	m := &nameMatcher{}
+	for _, token := range strings.Fields(query) {
+		runes := []rune(token)
+		lower := []rune(strings.ToLower(token))
+		if len(lower) != len(runes) {
+			lower = runes
+		}
+		m.tokens = append(m.tokens, runes)
+		m.lowerTokens = append(m.lowerTokens, lower)
+	}
+	return m
+}
+
+func (m *nameMatcher) match(name string) (match, bool) {
+	if len(m.tokens) == 0 {
+		return match{}, false
+	}
+
+	// Most names don't match, reject them before allocating anything
+	for _, token := range m.lowerTokens {
+		if !hasSubsequence(name, token) {
+			return match{}, false
+		}
+	}
+
+	nameRunes := []rune(name)
+	lowerName := []rune(strings.ToLower(name))
+	if len(lowerName) != len(nameRunes) {
+		lowerName = nameRunes
+	}
+
+	var result match
+	seen := make(map[int]bool)
+	for i, token := range m.tokens {
+		score, positions, ok := matchToken(token, m.lowerTokens[i], nameRunes, lowerName)
+		if !ok {
+			return match{}, false
+		}
+		result.score += score
+		for _, p := range positions {
+			if !seen[p] {
+				seen[p] = true
+				result.positions = append(result.positions, p)
+			}
+		}
+	}
+
+	sort.Ints(result.positions)
+	return result, true
+}
+
+// hasSubsequence reports whether the lower-cased token is a case-insensitive
+// subsequence of name
+func hasSubsequence(name string, token []rune) bool {
+	i := 0
+	for _, c := range name {
+		if i == len(token) {
+			break
+		}
+		if unicode.ToLower(c) == token[i] {
+			i++
+		}
+	}
+	return i == len(token)
+}
+
+// boundaryBonus rates how well position j of a name starts a word
+func boundaryBonus(name []rune, j int) int {
+	if j == 0 {
+		return matchStartBonus
+	}
+	prev, cur := name[j-1], name[j]
+	switch {
+	case isSeparator(prev):
+		return matchSeparatorBonus
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return matchHumpBonus
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return matchHumpBonus
+	}
+	return 0
+}
+
+// matchToken finds the best scoring subsequence match of a token in a
+// name. best[i][j] is the best score with token rune i matched at name
+// rune j; gaps before the first match are free.
+func matchToken(token, lowerToken, name, lowerName []rune) (int, []int, bool) {
+	n, m := len(token), len(name)
+	if n == 0 || n > m {
+		return 0, nil, n == 0
+	}
+
+	const none = -1 << 30
+	best := make([][]int, n)
+	from := make([][]int, n)
+	for i := range best {
+		best[i] = make([]int, m)
+		from[i] = make([]int, m)
+	}
+
+	for i := 0; i < n; i++ {
+		// Best predecessor so far, adjusted for the gap up to j
+		runBest, runFrom := none, -1
+		for j := 0; j < m; j++ {
+			best[i][j] = none
+			from[i][j] = -1
+
+			if i > 0 && j > 0 {
+				// Extend the running best by one skipped character
+				if runBest != none {
+					runBest -= matchGapPenalty
+				}
+				// best[i-1][j-2] becomes reachable with a gap of one
+				if j >= 2 && best[i-1][j-2] != none && best[i-1][j-2]-matchGapPenalty > runBest {
+					runBest, runFrom = best[i-1][j-2]-matchGapPenalty, j-2
+				}
+			}
+
+			if lowerName[j] != lowerToken[i] {
+				continue
+			}
+
+			score := matchCharScore + boundaryBonus(name, j)
+			if name[j] == token[i] {
+				score += matchExactCaseBonus
+			}
+
+			if i == 0 {
+				best[i][j] = score
+				continue
+			}
+
+			prev, prevFrom := runBest, runFrom
+			if j > 0 && best[i-1][j-1] != none && best[i-1][j-1]+matchConsecutiveBonus > prev {
+				prev, prevFrom = best[i-1][j-1]+matchConsecutiveBonus, j-1
+			}
+			if prev == none {
+				continue
+			}
+			best[i][j] = prev + score
+			from[i][j] = prevFrom
+		}
+	}
+
+	end, score := -1, none
+	for j := 0; j < m; j++ {
+		if best[n-1][j] > score {
+			end, score = j, best[n-1][j]
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, n)
+	for i, j := n-1, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return score, positions, true
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNameMatcher(t *testing.T) {
+	tests := []struct {
+		query     string
+		name      string
+		positions []int // nil when the name doesn't match
+	}{
+		{query: "gebi", name: "getElementById", positions: []int{0, 3, 10, 12}},
+		{query: "ospj", name: "os.path.join", positions: []int{0, 1, 3, 8}},
+		{query: "join", name: "os.path.join", positions: []int{8, 9, 10, 11}},
+		{query: "array map", name: "Array.prototype.map()", positions: []int{0, 1, 2, 3, 4, 16, 17, 18}},
+		{query: "map array", name: "Array.prototype.map()", positions: []int{0, 1, 2, 3, 4, 16, 17, 18}},
+		{query: "xyz", name: "os.path.join"},
+		{query: "map set", name: "Array.prototype.map()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.name, func(t *testing.T) {
+			m, ok := newNameMatcher(tt.query).match(tt.name)
+			if ok != (tt.positions != nil) {
+				t.Fatalf("match() ok = %v, want %v", ok, tt.positions != nil)
+			}
+			if ok && !reflect.DeepEqual(m.positions, tt.positions) {
+				t.Errorf("match() positions = %v, want %v", m.positions, tt.positions)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  searchQuery
+	}{
+		{
+			query: "join",
+			want:  searchQuery{text: "join"},
+		},
+		{
+			query: "array map",
+			want:  searchQuery{text: "array map"},
+		},
+		{
+			query: "type:Method in:python path:library/os join",
+			want: searchQuery{
+				text:    "join",
+				types:   []string{"method"},
+				docsets: []string{"python"},
+				paths:   []string{"library/os"},
+			},
+		},
+		{
+			query: "-type:constant -in:php -path:reference join",
+			want: searchQuery{
+				text:       "join",
+				notTypes:   []string{"constant"},
+				notDocsets: []string{"php"},
+				notPaths:   []string{"reference"},
+			},
+		},
+		{
+			query: "in:python in:node~18_lts map",
+			want:  searchQuery{text: "map", docsets: []string{"python", "node~18_lts"}},
+		},
+		{
+			query: "std::vector in: -foo",
+			want:  searchQuery{text: "std::vector in: -foo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := parseQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchQueryResolveDocsets(t *testing.T) {
+	installed := map[string]string{
+		"python":        "python",
+		"python~3.12":   "python",
+		"node":          "node.js",
+		"node.js":       "node.js",
+		"ruby on rails": "ruby on rails",
+		"rails~7.1":     "ruby on rails",
+		"python~3.10":   "python~3.10",
+		"javascript":    "javascript",
+	}
+
+	q := parseQuery("in:node in:python~3.12 in:ruby_on_rails -in:python~3.10 -in:missing join")
+	q.resolveDocsets(installed)
+
+	if want := []string{"node.js", "python", "ruby on rails"}; !reflect.DeepEqual(q.docsets, want) {
+		t.Errorf("docsets = %v, want %v", q.docsets, want)
+	}
+	if want := []string{"python~3.10", "missing"}; !reflect.DeepEqual(q.notDocsets, want) {
+		t.Errorf("notDocsets = %v, want %v", q.notDocsets, want)
+	}
+	if !q.accepts("node.js", DocumentEntry{Name: "path.join"}) {
+		t.Error("accepts() rejected an entry of node.js for in:node")
+	}
+	if q.accepts("python~3.10", DocumentEntry{Name: "os.path.join"}) {
+		t.Error("accepts() kept an entry of python~3.10 for -in:python~3.10")
+	}
+}
+
+func TestNameRelevanceOrder(t *testing.T) {
+	// Ordered from the most to the least relevant for "join"
+	want := []string{
+		"join",             // Exact
+		"Join",             // Exact, ignoring case
+		"str.join",         // Last dotted segment
+		"os.path.join",     // Last dotted segment, longer
+		"joinpath",         // Prefix
+		"os.path.joinpath", // Prefix of the last segment
+		"adjoin",           // Substring
+	}
+
+	got := make([]string, len(want))
+	for i, name := range want {
+		got[len(want)-1-i] = name
+	}
+	sort.SliceStable(got, func(i, j int) bool {
+		return nameRelevance("join", got[i]) > nameRelevance("join", got[j])
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ordered by nameRelevance() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// mcpProtocolVersions are the Model Context Protocol revisions ddc speaks,
+// oldest first. The newest one is offered when the client asks for another.
+var mcpProtocolVersions = []string{"2024-11-05", "2025-03-26"}
+
+const (
+	// Limits keeping tool results small enough for a context window
+	mcpDefaultResults = 20
+	mcpMaxResults     = 100
+	mcpMaxDocument    = 20000
+)
+
+type mcpRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "list_docsets",
+		Description: "List the documentation sets installed locally.",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	},
+	{
+		Name:        "search_entries",
+		Description: "Fuzzy search entry names of installed documentation sets. Returns docset, name, type and path of the best matches.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query":  map[string]any{"type": "string", "description": "Entry name to search for, e.g. Array.prototype.map"},
+				"docset": map[string]any{"type": "string", "description": "Only search this docset"},
+				"limit":  map[string]any{"type": "integer", "description": fmt.Sprintf("Maximum number of results, at most %d", mcpMaxResults)},
+			},
+			"required": []string{"query"},
+		},
+	},
+	{
+		Name:        "get_document",
+		Description: "Get the documentation of an entry as markdown. Paths with a #fragment return only that section.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"docset": map[string]any{"type": "string", "description": "Docset the entry belongs to"},
+				"path":   map[string]any{"type": "string", "description": "Entry path as returned by search_entries"},
+			},
+			"required": []string{"docset", "path"},
+		},
+	},
+}
+
+// MCPServer answers Model Context Protocol requests about installed docs
+type MCPServer struct {
+	cache  *Cache
+	client *DevDoc
+}
+
+func NewMCPServer(cache *Cache, client *DevDoc) *MCPServer {
+	return &MCPServer{cache: cache, client: client}
+}
+
+// Serve reads newline delimited JSON-RPC messages from r and writes
+// responses to w until r is exhausted
+func (s *MCPServer) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var req mcpRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			if err := encoder.Encode(mcpResponse{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage("null"),
+				Error:   &mcpError{Code: -32700, Message: "parse error"},
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Notifications don't get a response
+		if len(req.ID) == 0 {
+			continue
+		}
+
+		result, rpcErr := s.handle(req)
+		resp := mcpResponse{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *MCPServer) handle(req mcpRequest) (any, *mcpError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+
+		version := mcpProtocolVersions[len(mcpProtocolVersions)-1]
+		if slices.Contains(mcpProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "ddc", "version": "dev"},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": mcpTools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &mcpError{Code: -32602, Message: "invalid params"}
+		}
+
+		text, err := s.callTool(params.Name, params.Arguments)
+		if err != nil {
+			return mcpToolResult{
+				Content: []mcpContent{{Type: "text", Text: err.Error()}},
+				IsError: true,
+			}, nil
+		}
+		return mcpToolResult{Content: []mcpContent{{Type: "text", Text: text}}}, nil
+	}
+
+	return nil, &mcpError{Code: -32601, Message: "method not found: " + req.Method}
+}
+
+func (s *MCPServer) callTool(name string, arguments json.RawMessage) (string, error) {
+	var args struct {
+		Query  string `json:"query"`
+		Docset string `json:"docset"`
+		Path   string `json:"path"`
+		Limit  int    `json:"limit"`
+	}
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	switch name {
+	case "list_docsets":
+		return s.listDocsets()
+	case "search_entries":
+		if args.Query == "" {
+			return "", fmt.Errorf("query is required")
+		}
+		return s.searchEntries(args.Query, args.Docset, args.Limit)
+	case "get_document":
+		if args.Docset == "" || args.Path == "" {
+			return "", fmt.Errorf("docset and path are required")
+		}
+		return s.getDocument(args.Docset, args.Path)
+	}
+	return "", fmt.Errorf("unknown tool: %s", name)
+}
+
+func (s *MCPServer) listDocsets() (string, error) {
+	slugs, err := installedDocsets(s.cache)
+	if err != nil {
+		return "", err
+	}
+
+	type docset struct {
+		Docset  string `json:"docset"`
+		Release string `json:"release,omitempty"`
+	}
+	docsets := make([]docset, 0, len(slugs))
+	for _, slug := range slugs {
+		meta, _ := s.cache.GetMeta(slug)
+		docsets = append(docsets, docset{Docset: slug, Release: meta.Release})
+	}
+
+	data, err := json.Marshal(docsets)
+	return string(data), err
+}
+
+func (s *MCPServer) searchEntries(query, docset string, limit int) (string, error) {
+	if limit <= 0 {
+		limit = mcpDefaultResults
+	}
+	limit = min(limit, mcpMaxResults)
+
+	var docsets []string
+	if docset != "" {
+		docsets = append(docsets, docset)
+	}
+	results, err := searchDocsets(s.cache, query, limit, docsets...)
+	if err != nil {
+		return "", err
+	}
+	type entry struct {
+		Docset string `json:"docset"`
+		Name   string `json:"name"`
+		Type   string `json:"type"`
+		Path   string `json:"path"`
+	}
+	entries := make([]entry, len(results))
+	for i, r := range results {
+		entries[i] = entry{Docset: r.docset, Name: r.entry.Name, Type: r.entry.Type, Path: r.entry.Path}
+	}
+
+	data, err := json.Marshal(entries)
+	return string(data), err
+}
+
+func (s *MCPServer) getDocument(docset, path string) (string, error) {
+	if !s.client.IsDocSetInstalled(docset) {
+		return "", fmt.Errorf("documentation %s is not installed", docset)
+	}
+
+	entry := DocumentEntry{Path: path}
+	base, fragment := entry.SplitFragment()
+
+	content, err := s.client.GetDocument(docset, base)
+	if err != nil {
+		return "", err
+	}
+
+	markdown, err := htmlToMarkdown(content, fragment)
+	if err != nil {
+		return "", err
+	}
+	return truncateText(markdown, mcpMaxDocument), nil
+}
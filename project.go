@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectFileName is the file listing the docsets a project uses
+const projectFileName = ".ddc"
+
+// ProjectDocset is a docset listed in a project file, with an optional
+// version
+type ProjectDocset struct {
+	Name    string
+	Version string
+}
+
+// Project is a parsed project file. Docsets are listed in order of priority.
+type Project struct {
+	Path    string
+	Docsets []ProjectDocset
+}
+
+// findProject looks for a project file in dir and its parents. It returns
+// nil if there is none.
+func findProject(dir string) (*Project, error) {
+	for {
+		path := filepath.Join(dir, projectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return parseProject(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// parseProject reads a project file. Each line names a docset, optionally
+// followed by a version as "python 3.12", "python@3.12" or "python~3.12".
+// Lines starting with # are comments.
+func parseProject(path string) (*Project, error) {
+	project := &Project{Path: path}
+
+	for i, line := range readLines(path) {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("%s:%d: expected a docset and an optional version", path, i+1)
+		}
+
+		docset := ProjectDocset{Name: strings.ToLower(fields[0])}
+		if len(fields) == 2 {
+			docset.Version = fields[1]
+		} else if name, version, ok := strings.Cut(docset.Name, "@"); ok {
+			docset.Name, docset.Version = name, version
+		} else if name, version, ok := strings.Cut(docset.Name, "~"); ok {
+			docset.Name, docset.Version = name, version
+		}
+		project.Docsets = append(project.Docsets, docset)
+	}
+
+	return project, nil
+}
+
+// currentProject returns the project the working directory belongs to, or
+// nil if it doesn't belong to one
+func currentProject() (*Project, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return findProject(dir)
+}
+
+// InstalledDocsets returns the installed docsets of the project in order
+// of priority. A docset pinned to a version that isn't installed is left
+// out; ddc sync installs it.
+func (p *Project) InstalledDocsets(cache *Cache) []string {
+	installed := cache.InstalledSlugs()
+
+	var slugs []string
+	seen := make(map[string]bool)
+	for _, docset := range p.Docsets {
+		dir, ok := docset.installedDir(cache, installed)
+		if !ok || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		slugs = append(slugs, dir)
+	}
+	return slugs
+}
+
+// installedDir returns the directory a project docset is installed in. With
+// a version, that is the directory holding the most specific installed
+// version the given one falls in, as closestVersion picks from the catalog.
+func (d ProjectDocset) installedDir(cache *Cache, installed map[string]string) (string, bool) {
+	family, ok := installed[d.Name]
+	if !ok || d.Version == "" {
+		return family, ok
+	}
+	if dir, ok := installed[d.Name+"~"+d.Version]; ok {
+		return dir, true
+	}
+
+	dirs, err := installedDocsets(cache)
+	if err != nil {
+		return "", false
+	}
+	best, bestVersion := "", ""
+	for _, dir := range dirs {
+		if docsetFamily(cache, dir) != docsetFamily(cache, family) {
+			continue
+		}
+		meta, err := cache.GetMeta(dir)
+		if err != nil || meta.Version == "" || len(meta.Version) <= len(bestVersion) {
+			continue
+		}
+		if d.Version == meta.Version || strings.HasPrefix(d.Version, meta.Version+".") {
+			best, bestVersion = dir, meta.Version
+		}
+	}
+	return best, best != ""
+}
+
+// projectDocsets returns the installed docsets of the current project, or
+// nil when outside a project. A project file that can't be parsed is an
+// error rather than a silently unscoped search.
+func projectDocsets(cache *Cache) ([]string, error) {
+	project, err := currentProject()
+	if err != nil || project == nil {
+		return nil, err
+	}
+	return project.InstalledDocsets(cache), nil
+}
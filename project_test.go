@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProjectInstalledDocsets(t *testing.T) {
+	cache := &Cache{BaseDir: t.TempDir()}
+	for dir, meta := range map[string]DocMeta{
+		"python":      {Slug: "python~3.12", Version: "3.12"},
+		"python~3.10": {Slug: "python~3.10", Version: "3.10"},
+		"node.js":     {Slug: "node"},
+	} {
+		if err := cache.EnsureDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.SaveMeta(dir, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		docset ProjectDocset
+		want   []string
+	}{
+		{ProjectDocset{Name: "python"}, []string{"python"}},
+		{ProjectDocset{Name: "python", Version: "3.12"}, []string{"python"}},
+		{ProjectDocset{Name: "python", Version: "3.10"}, []string{"python~3.10"}},
+		{ProjectDocset{Name: "python", Version: "3.10.4"}, []string{"python~3.10"}},
+		{ProjectDocset{Name: "python", Version: "3.9"}, nil},
+		{ProjectDocset{Name: "node"}, []string{"node.js"}},
+		{ProjectDocset{Name: "ruby"}, nil},
+	}
+	for _, tt := range tests {
+		project := &Project{Docsets: []ProjectDocset{tt.docset}}
+		if got := project.InstalledDocsets(cache); !slices.Equal(got, tt.want) {
+			t.Errorf("InstalledDocsets() of %s %s = %v, want %v", tt.docset.Name, tt.docset.Version, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/v2/list"
@@ -263,6 +264,18 @@ func (m ProviderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 			if i, ok := m.list.SelectedItem().(Documentation); ok {
+				// Another version of an installed family goes next to it
+				if i.isVersion {
+					dir := versionDir(m.cache, i)
+					if dir != i.Kind() && !m.cache.DocsetExists(dir) {
+						docToDownload := i
+						m.downloading = docToDownload.Slug
+						return m, func() tea.Msg {
+							err := m.client.DownloadDocSetTo(&docToDownload, dir)
+							return downloadMsg{slug: docToDownload.Slug, success: err == nil, err: err}
+						}
+					}
+				}
 				if !m.cache.DocsetExists(i.Kind()) {
 					docToDownload := i
 					if !i.isVersion {
@@ -273,7 +286,7 @@ func (m ProviderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					m.downloading = docToDownload.Slug
 					return m, func() tea.Msg {
-						err := m.client.DownloadDocSet(&docToDownload)
+						err := m.client.DownloadDocSetTo(&docToDownload, versionDir(m.cache, docToDownload))
 						return downloadMsg{slug: docToDownload.Slug, success: err == nil, err: err}
 					}
 				}
@@ -351,15 +364,51 @@ func (m ProviderModel) GetSelected() []Documentation {
 	return selected
 }
 
-func ListDocumentations() ([]Documentation, error) {
+// ListDocumentations fetches the devdocs.io catalog, grouped by docset
+// family. The catalog is kept in the cache for CachedDocumentations.
+func ListDocumentations(cache *Cache) ([]Documentation, error) {
 	resp, err := http.Get("https://devdocs.io/docs.json")
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := groupDocumentations(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a failure to remember the catalog, listing still works
+	if err := os.MkdirAll(cache.BaseDir, 0755); err == nil {
+		os.WriteFile(cache.catalogPath(), data, 0644)
+	}
+	return docs, nil
+}
+
+// CachedDocumentations returns the catalog as last fetched, without going
+// online
+func CachedDocumentations(cache *Cache) ([]Documentation, error) {
+	data, err := os.ReadFile(cache.catalogPath())
+	if err != nil {
+		return nil, err
+	}
+	return groupDocumentations(data)
+}
+
+func (c *Cache) catalogPath() string {
+	return filepath.Join(c.BaseDir, "docs.json")
+}
+
+// groupDocumentations parses the catalog and groups the versions of each
+// docset family
+func groupDocumentations(data []byte) ([]Documentation, error) {
 	var allDocs []Documentation
-	if err := json.NewDecoder(resp.Body).Decode(&allDocs); err != nil {
+	if err := json.Unmarshal(data, &allDocs); err != nil {
 		return nil, err
 	}
 
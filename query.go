@@ -0,0 +1,186 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/list"
+)
+
+// searchQuery is a search with its qualifiers split off. Qualifiers of the
+// same kind are alternatives, negated ones exclude matches.
+//
+//	type:method in:python path:library/os -type:constant join
+type searchQuery struct {
+	text       string // Matched against entry names, see nameMatcher
+	types      []string
+	notTypes   []string
+	docsets    []string
+	notDocsets []string
+	paths      []string
+	notPaths   []string
+}
+
+// parseQuery splits type:, in: and path: qualifiers off a search query.
+// A leading - negates a qualifier.
+func parseQuery(query string) searchQuery {
+	var q searchQuery
+	var text []string
+
+	for _, word := range strings.Fields(query) {
+		negated := strings.HasPrefix(word, "-")
+		key, value, ok := strings.Cut(strings.TrimPrefix(word, "-"), ":")
+		if !ok || value == "" {
+			text = append(text, word)
+			continue
+		}
+
+		value = strings.ToLower(value)
+		switch strings.ToLower(key) {
+		case "type":
+			if negated {
+				q.notTypes = append(q.notTypes, value)
+			} else {
+				q.types = append(q.types, value)
+			}
+		case "in":
+			if negated {
+				q.notDocsets = append(q.notDocsets, value)
+			} else {
+				q.docsets = append(q.docsets, value)
+			}
+		case "path":
+			if negated {
+				q.notPaths = append(q.notPaths, value)
+			} else {
+				q.paths = append(q.paths, value)
+			}
+		default:
+			text = append(text, word)
+		}
+	}
+
+	q.text = strings.Join(text, " ")
+	return q
+}
+
+// resolveDocsets replaces the values of in: and -in: qualifiers with the
+// directories the docsets are installed in, so they take devdocs slugs as
+// well as names: in:node finds node.js and in:python~3.12 the python
+// directory. Underscores stand for spaces, as in in:ruby_on_rails. Values
+// that aren't installed are kept.
+func (q *searchQuery) resolveDocsets(installed map[string]string) {
+	for _, values := range [][]string{q.docsets, q.notDocsets} {
+		for i, value := range values {
+			if dir, ok := installed[value]; ok {
+				values[i] = dir
+			} else if dir, ok := installed[strings.ReplaceAll(value, "_", " ")]; ok {
+				values[i] = dir
+			}
+		}
+	}
+}
+
+// matchesAny reports whether value matches one of the qualifier values
+func matchesAny(value string, qualifiers []string, match func(string, string) bool) bool {
+	value = strings.ToLower(value)
+	for _, qualifier := range qualifiers {
+		if match(value, qualifier) {
+			return true
+		}
+	}
+	return false
+}
+
+func equals(a, b string) bool { return a == b }
+
+// accepts reports whether an entry of a docset passes the qualifiers.
+// Types match by substring, paths by prefix and docsets exactly.
+func (q searchQuery) accepts(docset string, entry DocumentEntry) bool {
+	if len(q.types) > 0 && !matchesAny(entry.Type, q.types, strings.Contains) {
+		return false
+	}
+	if matchesAny(entry.Type, q.notTypes, strings.Contains) {
+		return false
+	}
+	if len(q.paths) > 0 && !matchesAny(entry.Path, q.paths, strings.HasPrefix) {
+		return false
+	}
+	if matchesAny(entry.Path, q.notPaths, strings.HasPrefix) {
+		return false
+	}
+	if len(q.docsets) > 0 && !matchesAny(docset, q.docsets, equals) {
+		return false
+	}
+	return !matchesAny(docset, q.notDocsets, equals)
+}
+
+// filterValueSeparator separates the fields packed into a search result's
+// filter value, so the TUI filter can apply qualifiers
+const filterValueSeparator = "\x00"
+
+func packFilterValue(docset string, entry DocumentEntry) string {
+	return strings.Join([]string{entry.Name, entry.Type, entry.Path, docset}, filterValueSeparator)
+}
+
+func unpackFilterValue(value string) (string, DocumentEntry) {
+	fields := strings.SplitN(value, filterValueSeparator, 4)
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+	return fields[3], DocumentEntry{Name: fields[0], Type: fields[1], Path: fields[2]}
+}
+
+// newSearchFilter returns the list filter of search results. It
+// understands the same qualifiers as the initial query.
+func newSearchFilter(cache *Cache) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		return searchFilter(cache, term, targets)
+	}
+}
+
+func searchFilter(cache *Cache, term string, targets []string) []list.Rank {
+	q := parseQuery(term)
+	if len(q.docsets) > 0 || len(q.notDocsets) > 0 {
+		q.resolveDocsets(cache.InstalledSlugs())
+	}
+
+	var ranks []list.Rank
+	var scores []int
+	matcher := newNameMatcher(q.text)
+	for i, target := range targets {
+		if target == "" {
+			continue // Group header
+		}
+		docset, entry := unpackFilterValue(target)
+		if !q.accepts(docset, entry) {
+			continue
+		}
+		if q.text == "" {
+			ranks = append(ranks, list.Rank{Index: i})
+			continue
+		}
+		if m, ok := matcher.match(entry.Name); ok {
+			ranks = append(ranks, list.Rank{Index: i, MatchedIndexes: m.positions})
+			scores = append(scores, m.score+nameRelevance(q.text, entry.Name))
+		}
+	}
+
+	if q.text != "" {
+		sort.Stable(rankSorter{ranks, scores})
+	}
+	return ranks
+}
+
+// rankSorter orders list ranks by descending score
+type rankSorter struct {
+	ranks  []list.Rank
+	scores []int
+}
+
+func (s rankSorter) Len() int           { return len(s.ranks) }
+func (s rankSorter) Less(i, j int) bool { return s.scores[i] > s.scores[j] }
+func (s rankSorter) Swap(i, j int) {
+	s.ranks[i], s.ranks[j] = s.ranks[j], s.ranks[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Relevance weights for how the query text relates to an entry name
+const (
+	exactMatchScore      = 1000
+	exactFoldMatchScore  = 900
+	lastSegmentScore     = 600
+	prefixMatchScore     = 500
+	segmentMatchScore    = 400
+	segmentPrefixScore   = 300
+	wordBoundaryScore    = 200
+	substringMatchScore  = 100
+	typeMatchScore       = 50
+	docsetOrderScore     = 20 // Per position, for docsets searched in a given order
+	nameLengthPenaltyCap = 100
+)
+
+// RankingConfig tunes search ranking, read from ranking.json in the cache
+// directory:
+//
+//	{"docsets": {"python": 100}, "types": {"constant": -50}}
+type RankingConfig struct {
+	Docsets map[string]int `json:"docsets"` // Bonus for entries of a docset
+	Types   map[string]int `json:"types"`   // Bonus for entry types containing the key
+}
+
+func (c *Cache) GetRankingConfig() (RankingConfig, error) {
+	var config RankingConfig
+
+	data, err := os.ReadFile(filepath.Join(c.BaseDir, "ranking.json"))
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse ranking.json: %w", err)
+	}
+	return config, nil
+}
+
+// ranker scores search results the same way across docsets
+type ranker struct {
+	frecency map[string]int
+	config   RankingConfig
+	priority map[string]int // Bonus from the order docsets were given in
+}
+
+// newRanker creates a ranker. Docsets given in an explicit order, such as
+// the ones of a project file, rank higher the earlier they are listed.
+func newRanker(cache *Cache, docsets []string) *ranker {
+	config, _ := cache.GetRankingConfig()
+	r := &ranker{
+		frecency: cache.Frecency(),
+		config:   config,
+	}
+	return r.withOrder(docsets)
+}
+
+// withOrder returns a copy of the ranker for docsets given in another order
+func (r *ranker) withOrder(docsets []string) *ranker {
+	priority := make(map[string]int, len(docsets))
+	for i, slug := range docsets {
+		priority[slug] = (len(docsets) - i - 1) * docsetOrderScore
+	}
+
+	return &ranker{
+		frecency: r.frecency,
+		config:   r.config,
+		priority: priority,
+	}
+}
+
+// score rates an entry of a docset for the query text. fuzzyScore is the
+// score of the underlying subsequence match.
+func (r *ranker) score(slug string, entry DocumentEntry, text string, fuzzyScore int) int {
+	score := fuzzyScore + nameRelevance(text, entry.Name)
+
+	entryType := strings.ToLower(entry.Type)
+	if text != "" && strings.Contains(entryType, strings.ToLower(text)) {
+		score += typeMatchScore
+	}
+	for key, bonus := range r.config.Types {
+		if strings.Contains(entryType, strings.ToLower(key)) {
+			score += bonus
+		}
+	}
+
+	score += r.config.Docsets[slug]
+	score += r.priority[slug]
+	score += r.frecency[frecencyKey(slug, entry.Path)]
+	return score
+}
+
+// isSeparator reports whether c separates the segments of a symbol name,
+// as in os.path.join, std::vector, $obj->method or snake_case
+func isSeparator(c rune) bool {
+	switch c {
+	case '.', ':', '-', '>', '/', '#', '_', ' ', '(', ')', '$', '\\':
+		return true
+	}
+	return false
+}
+
+// nameSegments splits a lower-cased name into its segments
+func nameSegments(name string) []string {
+	return strings.FieldsFunc(name, isSeparator)
+}
+
+// atWordBoundary reports whether byte i of name starts a word: the start
+// of the name, the character after a separator or a camelCase hump
+func atWordBoundary(name string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	if i >= len(name) {
+		return false
+	}
+	prev, cur := rune(name[i-1]), rune(name[i])
+	return isSeparator(prev) || (unicode.IsLower(prev) && unicode.IsUpper(cur))
+}
+
+// nameRelevance scores how the query text relates to an entry name: exact
+// matches beat prefixes, which beat matches of a dotted segment, word
+// boundary and plain substring matches. Shorter names win ties.
+func nameRelevance(text, name string) int {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0
+	}
+
+	q := strings.ToLower(text)
+	bare := strings.TrimSuffix(name, "()")
+	lower := strings.ToLower(bare)
+	penalty := -min(len(name), nameLengthPenaltyCap)
+
+	switch {
+	case bare == text:
+		return penalty + exactMatchScore
+	case lower == q:
+		return penalty + exactFoldMatchScore
+	case strings.HasPrefix(lower, q):
+		return penalty + prefixMatchScore
+	}
+
+	if segments := nameSegments(lower); len(segments) > 1 {
+		last := segments[len(segments)-1]
+		switch {
+		case last == q || strings.HasSuffix(lower, "."+q) || strings.HasSuffix(lower, "::"+q):
+			return penalty + lastSegmentScore
+		case strings.HasPrefix(last, q):
+			return penalty + segmentPrefixScore
+		}
+		for _, segment := range segments {
+			if segment == q {
+				return penalty + segmentMatchScore
+			}
+		}
+	}
+
+	if !strings.Contains(lower, q) {
+		return penalty
+	}
+	for i := 0; i+len(q) <= len(lower); i++ {
+		if lower[i:i+len(q)] == q && atWordBoundary(bare, i) {
+			return penalty + wordBoundaryScore
+		}
+	}
+	return penalty + substringMatchScore
+}
+
+// betterResult reports whether a ranks before b: higher scores first, then
+// shorter names
+func betterResult(a, b searchResult) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	if len(a.entry.Name) != len(b.entry.Name) {
+		return len(a.entry.Name) < len(b.entry.Name)
+	}
+	if a.entry.Name != b.entry.Name {
+		return a.entry.Name < b.entry.Name
+	}
+	return a.docset < b.docset
+}
+
+// sortResults orders results by score, then by shorter name
+func sortResults(results []searchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return betterResult(results[i], results[j])
+	})
+}
+
+// resultHeap is a min-heap of results with the worst one on top
+type resultHeap []searchResult
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return betterResult(h[j], h[i]) }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x any)        { *h = append(*h, x.(searchResult)) }
+func (h *resultHeap) Pop() any {
+	old := *h
+	result := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return result
+}
+
+// topResults keeps the best results pushed to it, up to limit. A limit
+// that isn't positive keeps all of them.
+type topResults struct {
+	limit   int
+	results resultHeap
+}
+
+func (t *topResults) push(result searchResult) {
+	switch {
+	case t.limit <= 0:
+		t.results = append(t.results, result)
+	case len(t.results) < t.limit:
+		heap.Push(&t.results, result)
+	case betterResult(result, t.results[0]):
+		t.results[0] = result
+		heap.Fix(&t.results, 0)
+	}
+}
+
+// sorted returns the kept results, best first
+func (t *topResults) sorted() []searchResult {
+	results := []searchResult(t.results)
+	sortResults(results)
+	return results
+}
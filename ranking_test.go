@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func BenchmarkTopResults(b *testing.B) {
+	var results []searchResult
+	for _, slug := range []string{"python", "javascript", "php", "cpp"} {
+		for _, entry := range benchmarkEntries(slug, 30000) {
+			results = append(results, searchResult{docset: slug, entry: entry})
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := range results {
+		results[i].score = rng.Intn(2000)
+	}
+
+	for _, limit := range []int{1, searchPageSize, 0} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				top := topResults{limit: limit}
+				for _, result := range results {
+					top.push(result)
+				}
+				top.sorted()
+			}
+		})
+	}
+}
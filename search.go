@@ -5,150 +5,397 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
+	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/list"
 	tea "github.com/charmbracelet/bubbletea/v2"
-	"github.com/sahilm/fuzzy"
+	"github.com/charmbracelet/lipgloss/v2"
 )
 
 type searchResult struct {
 	docset  string
 	entry   DocumentEntry
 	matches []int // Positions of matches in the name
+	score   int
+
+	// Versions of the docset family the entry is in and missing from, when
+	// searching all versions, see searchVersions
+	versions []string
+	missing  []string
+}
+
+// FilterValue packs the fields qualifiers filter on, see searchFilter
+func (s searchResult) FilterValue() string { return packFilterValue(s.docset, s.entry) }
+
+// searchHeader heads the results of a docset when results are grouped
+type searchHeader struct {
+	docset string
+	count  int
 }
 
-func (s searchResult) FilterValue() string { return s.entry.Name }
+// FilterValue is empty so the filter drops headers, see searchFilter
+func (h searchHeader) FilterValue() string { return "" }
 
-type searchDelegate struct{}
+// searchDelegate renders a result as its docset and release, the name with
+// the matched characters highlighted, the entry type and, when the list is
+// wide enough, a dimmed path
+type searchDelegate struct {
+	releases map[string]string // Release of each docset, from its meta
+}
 
 func (d searchDelegate) Height() int                             { return 1 }
 func (d searchDelegate) Spacing() int                            { return 0 }
 func (d searchDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 func (d searchDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if h, ok := listItem.(searchHeader); ok {
+		fmt.Fprint(w, headerStyle.Render(fmt.Sprintf("%s (%d)", d.docsetName(h.docset), h.count)))
+		return
+	}
+
 	i, ok := listItem.(searchResult)
 	if !ok {
 		return
 	}
 
-	str := fmt.Sprintf("%s: %s", i.docset, i.entry.Name)
-
-	fn := itemStyle.Render
+	style := itemStyle
 	if index == m.Index() {
-		fn = selectedItemStyle.Render
+		style = selectedItemStyle
+	}
+	padding := style.GetPaddingLeft()
+	style = style.UnsetPaddingLeft()
+
+	// Matches of the list filter replace the ones of the initial query
+	matches := i.matches
+	if m.FilterState() != list.Unfiltered {
+		matches = m.MatchesForItem(index)
 	}
 
-	fmt.Fprint(w, fn(str))
+	docset := d.docsetName(i.docset)
+	if len(i.versions) > 0 {
+		docset = i.docset // The versions follow
+	}
+
+	row := style.Render(docset+": ") +
+		lipgloss.StyleRunes(i.entry.Name, matches, style.Inherit(matchedStyle), style)
+	if i.entry.Type != "" {
+		row += style.Render("  ") + style.Italic(true).Render(i.entry.Type)
+	}
+	if len(i.versions) > 0 {
+		row += style.Render("  in " + strings.Join(i.versions, ", "))
+	}
+	if len(i.missing) > 0 {
+		row += dimmedStyle.Render("  not in " + strings.Join(i.missing, ", "))
+	}
+
+	gap := m.Width() - padding - lipgloss.Width(row) - lipgloss.Width(i.entry.Path)
+	if gap >= 2 {
+		row += strings.Repeat(" ", gap) + dimmedStyle.Render(i.entry.Path)
+	}
+
+	fmt.Fprint(w, strings.Repeat(" ", padding)+row)
+}
+
+func (d searchDelegate) docsetName(slug string) string {
+	if release := d.releases[slug]; release != "" {
+		return slug + " " + release
+	}
+	return slug
+}
+
+// groupResults puts the results of each docset under a header with their
+// count. Docsets are ordered by their best result.
+func groupResults(results []searchResult) []list.Item {
+	var order []string
+	groups := make(map[string][]searchResult)
+	for _, result := range results {
+		if _, ok := groups[result.docset]; !ok {
+			order = append(order, result.docset)
+		}
+		groups[result.docset] = append(groups[result.docset], result)
+	}
+
+	items := make([]list.Item, 0, len(results)+len(order))
+	for _, slug := range order {
+		items = append(items, searchHeader{docset: slug, count: len(groups[slug])})
+		for _, result := range groups[slug] {
+			items = append(items, result)
+		}
+	}
+	return items
 }
 
 type SearchModel struct {
-	list   list.Model
-	cache  *Cache
-	query  string
-	docset string // Optional docset to search within
-	err    error
+	list        list.Model
+	cache       *Cache
+	query       string
+	docsets     []string // Optional docsets to search within
+	results     []searchResult
+	grouped     bool // Whether results are grouped by docset
+	limit       int  // Number of results to show, all if not positive
+	allVersions bool // Whether all installed versions of docsets are searched
+	status      string
+	err         error
 }
 
-// NewSearchModel creates a search model that searches across all documentations
-// or within a specific docset if specified
-func NewSearchModel(cache *Cache, query string, docset ...string) (SearchModel, error) {
-	var specificDocset string
-	if len(docset) > 0 && docset[0] != "" {
-		specificDocset = docset[0]
+// loadEntries returns the entries of an installed docset, from memory when
+// running as the daemon
+func loadEntries(cache *Cache, slug string) ([]DocumentEntry, error) {
+	if cache.store != nil {
+		return cache.store.entries(slug)
 	}
+	return readEntries(cache, slug)
+}
 
-	var results []list.Item
+// readEntries reads and parses the index of an installed docset, followed
+// by the sections extracted from its pages
+func readEntries(cache *Cache, slug string) ([]DocumentEntry, error) {
+	indexData, err := cache.GetIndex(slug)
+	if err != nil {
+		return nil, err
+	}
 
-	// If a specific docset is provided, only search within that docset
-	if specificDocset != "" {
-		// Check if the docset exists
-		indexData, err := cache.GetIndex(specificDocset)
-		if err != nil {
-			return SearchModel{}, fmt.Errorf("documentation %s is not installed: %w", specificDocset, err)
-		}
+	var index struct {
+		Entries []DocumentEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return nil, err
+	}
 
-		var index struct {
-			Entries []DocumentEntry `json:"entries"`
-		}
-		if err := json.Unmarshal(indexData, &index); err != nil {
-			return SearchModel{}, fmt.Errorf("failed to parse index for %s: %w", specificDocset, err)
+	sections, err := cache.GetSections(slug)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		index.Entries = append(index.Entries, section.Entry())
+	}
+	return index.Entries, nil
+}
+
+// installedDocsets returns the slugs of all installed docsets
+func installedDocsets(cache *Cache) ([]string, error) {
+	entries, err := os.ReadDir(cache.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var slugs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			slugs = append(slugs, entry.Name())
 		}
+	}
+	return slugs, nil
+}
 
-		// Create a slice of strings for fuzzy matching
-		names := make([]string, len(index.Entries))
-		for i, entry := range index.Entries {
-			names[i] = entry.Name
+// matchEntries matches the entries of a docset that pass the query's
+// qualifiers and returns the best limit of them scored by the ranker, or
+// all of them if limit isn't positive
+func matchEntries(slug string, entries []DocumentEntry, q searchQuery, r *ranker, limit int) []searchResult {
+	top := topResults{limit: limit}
+	matcher := newNameMatcher(q.text)
+	for _, entry := range entries {
+		if !q.accepts(slug, entry) {
+			continue
 		}
 
-		// Perform fuzzy search
-		matches := fuzzy.Find(query, names)
-		for _, match := range matches {
-			results = append(results, searchResult{
-				docset:  specificDocset,
-				entry:   index.Entries[match.Index],
-				matches: match.MatchedIndexes,
+		// Only qualifiers, every accepted entry matches
+		if q.text == "" {
+			top.push(searchResult{
+				docset: slug,
+				entry:  entry,
+				score:  r.score(slug, entry, "", 0),
 			})
+			continue
 		}
-	} else {
-		// Search across all installed documentations
-		entries, err := os.ReadDir(cache.BaseDir)
+
+		m, ok := matcher.match(entry.Name)
+		if !ok {
+			continue
+		}
+		top.push(searchResult{
+			docset:  slug,
+			entry:   entry,
+			matches: m.positions,
+			score:   r.score(slug, entry, q.text, m.score),
+		})
+	}
+	return top.sorted()
+}
+
+// searchDocsets searches across all installed documentations or within the
+// given docsets, earlier ones ranking higher. An in: qualifier in the query
+// replaces the given docsets. Docsets are loaded and matched in parallel
+// and only the best limit results of all of them are kept, all of them if
+// limit isn't positive. A running daemon answers instead when there is one.
+func searchDocsets(cache *Cache, query string, limit int, docsets ...string) ([]searchResult, error) {
+	if resp, ok := callDaemon(cache, daemonRequest{
+		Method:  "search",
+		Query:   query,
+		Docsets: docsets,
+		Limit:   limit,
+	}); ok {
+		return resp.searchResults(), resp.err()
+	}
+
+	q := parseQuery(query)
+	if len(q.docsets) > 0 || len(q.notDocsets) > 0 {
+		q.resolveDocsets(cache.InstalledSlugs())
+	}
+	if len(q.docsets) > 0 {
+		docsets = q.docsets
+	}
+	r := newRanker(cache, docsets)
+
+	// Without specific docsets, search all installed documentations and
+	// skip the ones that fail to load
+	explicit := len(docsets) > 0
+	if !explicit {
+		slugs, err := installedDocsets(cache)
 		if err != nil {
-			return SearchModel{}, err
+			return nil, err
 		}
+		docsets = slugs
+	}
 
-		for _, entry := range entries {
-			if !entry.IsDir() {
-				continue
-			}
+	type docsetResults struct {
+		results []searchResult
+		err     error
+	}
+	found := make([]docsetResults, len(docsets))
 
-			slug := entry.Name()
-			indexData, err := cache.GetIndex(slug)
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, slug := range docsets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			entries, err := loadEntries(cache, slug)
 			if err != nil {
-				continue
+				found[i].err = err
+				return
 			}
+			found[i].results = matchEntries(slug, entries, q, r, limit)
+		}()
+	}
+	wg.Wait()
 
-			var index struct {
-				Entries []DocumentEntry `json:"entries"`
-			}
-			if err := json.Unmarshal(indexData, &index); err != nil {
-				continue
+	top := topResults{limit: limit}
+	for i, f := range found {
+		if f.err != nil {
+			if explicit {
+				return nil, fmt.Errorf("documentation %s is not installed: %w", docsets[i], f.err)
 			}
+			continue
+		}
+		for _, result := range f.results {
+			top.push(result)
+		}
+	}
+	return top.sorted(), nil
+}
 
-			// Create a slice of strings for fuzzy matching
-			names := make([]string, len(index.Entries))
-			for i, entry := range index.Entries {
-				names[i] = entry.Name
-			}
+// Keys of the search results besides those of the list
+var (
+	groupKey    = key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "group by docset"))
+	versionsKey = key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "all versions"))
+	moreKey     = key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "load more"))
+)
 
-			// Perform fuzzy search
-			matches := fuzzy.Find(query, names)
-			for _, match := range matches {
-				results = append(results, searchResult{
-					docset:  slug,
-					entry:   index.Entries[match.Index],
-					matches: match.MatchedIndexes,
-				})
-			}
+// searchPageSize is how many results the search TUI shows at first and adds
+// with each "load more"
+const searchPageSize = 200
+
+// NewSearchModel creates a search model that searches across all documentations
+// or within a specific docset if specified
+func NewSearchModel(cache *Cache, query string, opts searchOptions, docset ...string) (SearchModel, error) {
+	var docsets []string
+	for _, slug := range docset {
+		if slug != "" {
+			docsets = append(docsets, slug)
 		}
 	}
 
 	title := fmt.Sprintf("Search results for '%s'", query)
-	if specificDocset != "" {
-		title += fmt.Sprintf(" in %s", specificDocset)
+	if len(docsets) > 0 {
+		title += fmt.Sprintf(" in %s", strings.Join(docsets, ", "))
 	}
 
-	l := list.New(results, searchDelegate{}, 80, 30)
+	l := list.New(nil, searchDelegate{}, 80, 30)
 	l.Title = title
 	l.SetShowStatusBar(true)
+	l.Filter = newSearchFilter(cache)
+	useKeys(&l, bookmarkKey, groupKey, versionsKey, moreKey)
 
-	return SearchModel{
-		list:   l,
-		cache:  cache,
-		query:  query,
-		docset: specificDocset,
-	}, nil
+	m := SearchModel{
+		list:        l,
+		cache:       cache,
+		query:       query,
+		docsets:     docsets,
+		grouped:     opts.group,
+		limit:       opts.limit,
+		allVersions: opts.allVersions,
+	}
+	if err := m.search(); err != nil {
+		return SearchModel{}, err
+	}
+	return m, nil
+}
+
+// search runs the query for the best m.limit results
+func (m *SearchModel) search() error {
+	search := searchDocsets
+	if m.allVersions {
+		search = searchVersions
+	}
+	found, err := search(m.cache, m.query, m.limit, m.docsets...)
+	if err != nil {
+		return err
+	}
+
+	releases := make(map[string]string)
+	for _, result := range found {
+		if _, ok := releases[result.docset]; !ok {
+			meta, _ := m.cache.GetMeta(result.docset)
+			releases[result.docset] = meta.Release
+		}
+	}
+
+	m.results = found
+	m.list.SetDelegate(searchDelegate{releases: releases})
+	m.setGrouped(m.grouped)
+
+	m.status = ""
+	if m.hasMore() {
+		m.status = fmt.Sprintf("Showing the best %d results, press m to load more", len(found))
+	}
+	return nil
+}
+
+// hasMore reports whether the results may have been cut off by the limit
+func (m SearchModel) hasMore() bool {
+	return m.limit > 0 && len(m.results) >= m.limit
+}
+
+// setGrouped shows the results grouped by docset or as one ranked list
+func (m *SearchModel) setGrouped(grouped bool) {
+	m.grouped = grouped
+	if grouped {
+		m.list.SetItems(groupResults(m.results))
+		m.list.Select(1) // Skip the first header
+		return
+	}
+
+	items := make([]list.Item, len(m.results))
+	for i, result := range m.results {
+		items[i] = result
+	}
+	m.list.SetItems(items)
 }
 
 func (m SearchModel) Init() (tea.Model, tea.Cmd) {
@@ -157,28 +404,61 @@ func (m SearchModel) Init() (tea.Model, tea.Cmd) {
 
 func (m SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height-4)
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "tab":
+			if m.list.SettingFilter() {
+				break
+			}
+			m.setGrouped(!m.grouped)
+			return m, nil
+		case "v":
+			if m.list.SettingFilter() {
+				break
+			}
+			m.allVersions = !m.allVersions
+			if err := m.search(); err != nil {
+				m.err = err
+			}
+			return m, nil
+		case "m":
+			if m.list.SettingFilter() || !m.hasMore() {
+				break
+			}
+			index := m.list.Index()
+			m.limit += searchPageSize
+			if err := m.search(); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.list.Select(index)
+			return m, nil
 		case "o":
 			if m.list.SettingFilter() {
 				break
 			}
 			if i, ok := m.list.SelectedItem().(searchResult); ok {
-				// Open the selected entry with lynx
-				htmlPath := filepath.Join(m.cache.GetHTMLDir(i.docset), strings.ReplaceAll(i.entry.Path, ".", "/")+".html")
-				cmd := exec.Command("lynx", htmlPath)
-				cmd.Stdin = os.Stdin
-				cmd.Stdout = os.Stdout
-				cmd.Stderr = os.Stderr
-				if err := cmd.Run(); err != nil {
-					m.err = fmt.Errorf("failed to open documentation: %w, trying %s", err, htmlPath)
+				if err := openEntry(m.cache, i.docset, i.entry); err != nil {
+					m.err = err
 					return m, nil
 				}
 				return m, tea.Quit
 			}
 			return m, nil
+		case "b":
+			if m.list.SettingFilter() {
+				break
+			}
+			if i, ok := m.list.SelectedItem().(searchResult); ok {
+				m.status, m.err = toggleBookmark(m.cache, i.docset, i.entry)
+			}
+			return m, nil
 		}
 	}
 
@@ -189,6 +469,9 @@ func (m SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m SearchModel) View() string {
 	view := "\n" + m.list.View()
+	if m.status != "" {
+		view += "\n" + m.status
+	}
 	if m.err != nil {
 		view += "\nError: " + m.err.Error()
 	}
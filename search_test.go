@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// benchmarkNames are the shapes of entry names in DevDocs indexes
+var benchmarkNames = []string{
+	"%s.path.join%d",
+	"%s.Element.getElementById%d",
+	"%s::vector::push_back%d",
+	"Array.prototype.%sMap%d",
+	"%s_array_key_exists%d",
+	"%s.TaskGroup.create_task%d",
+	"%s (%d)",
+}
+
+// benchmarkEntries generates n index entries for a docset
+func benchmarkEntries(slug string, n int) []DocumentEntry {
+	rng := rand.New(rand.NewSource(int64(len(slug))))
+	words := []string{"os", "string", "array", "http", "async", "node", "event", "buffer"}
+
+	entries := make([]DocumentEntry, n)
+	for i := range entries {
+		word := words[rng.Intn(len(words))]
+		name := fmt.Sprintf(benchmarkNames[rng.Intn(len(benchmarkNames))], word, i)
+		entries[i] = DocumentEntry{
+			Name: name,
+			Path: fmt.Sprintf("%s/page%d#%s", word, i/50, name),
+			Type: word,
+		}
+	}
+	return entries
+}
+
+// benchmarkCache installs docsets with generated indexes of n entries each
+// into a temporary cache
+func benchmarkCache(b *testing.B, docsets []string, n int) *Cache {
+	b.Helper()
+	cache := &Cache{BaseDir: b.TempDir()}
+	for _, slug := range docsets {
+		data, err := json.Marshal(map[string]any{"entries": benchmarkEntries(slug, n)})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := cache.EnsureDir(slug); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cache.GetDocPath(slug), "index.json"), data, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return cache
+}
+
+func BenchmarkSearchDocsets(b *testing.B) {
+	docsets := []string{"python", "javascript", "php", "cpp"}
+	cache := benchmarkCache(b, docsets, 30000)
+
+	for _, query := range []string{"join", "gebi", "os path join", "type:async task"} {
+		b.Run(query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := searchDocsets(cache, query, searchPageSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	// The daemon keeps indexes in memory, leaving only matching and ranking
+	b.Run("store", func(b *testing.B) {
+		stored := &Cache{BaseDir: cache.BaseDir}
+		stored.store = newIndexStore(stored)
+		stored.store.refresh()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := searchDocsets(stored, "join", searchPageSize); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSearchDocsetsInQualifier(t *testing.T) {
+	cache := &Cache{BaseDir: t.TempDir()}
+	for dir, meta := range map[string]DocMeta{
+		"node.js":       {Slug: "node"},
+		"python":        {Slug: "python~3.12"},
+		"ruby on rails": {Slug: "rails~7.1"},
+	} {
+		data, err := json.Marshal(map[string]any{"entries": []DocumentEntry{{Name: "join", Path: dir, Type: "x"}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.EnsureDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := cache.SaveMeta(dir, meta); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cache.GetDocPath(dir), "index.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"in:node join", []string{"node.js"}},
+		{"in:node.js join", []string{"node.js"}},
+		{"in:python~3.12 join", []string{"python"}},
+		{"in:rails join", []string{"ruby on rails"}},
+		{"in:ruby_on_rails join", []string{"ruby on rails"}},
+		{"-in:node -in:python~3.12 join", []string{"ruby on rails"}},
+	}
+	for _, tt := range tests {
+		results, err := searchDocsets(cache, tt.query, 0)
+		if err != nil {
+			t.Errorf("searchDocsets(%q) error = %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, result := range results {
+			got = append(got, result.docset)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("searchDocsets(%q) docsets = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// sectionType is the entry type of sections found in pages
+const sectionType = "Section"
+
+// Section is a heading or definition term with an id inside a page that the
+// docset index doesn't list, such as the parameters or events of an entry
+type Section struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`   // Page and anchor, as in entry paths
+	Parent string `json:"parent"` // Name of the index entry the section is part of
+}
+
+// Entry returns the section as an entry that searches and opens like the
+// ones of the index
+func (s Section) Entry() DocumentEntry {
+	name := s.Name
+	if s.Parent != "" {
+		name = fmt.Sprintf("%s (%s)", s.Name, s.Parent)
+	}
+	return DocumentEntry{Name: name, Path: s.Path, Type: sectionType}
+}
+
+func (c *Cache) sectionsPath(slug string) string {
+	return filepath.Join(c.BaseDir, slug, "sections.json")
+}
+
+// GetSections returns the sections of a docset. Docsets installed before
+// sections were extracted have none.
+func (c *Cache) GetSections(slug string) ([]Section, error) {
+	data, err := os.ReadFile(c.sectionsPath(slug))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []Section
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse sections: %w", err)
+	}
+	return sections, nil
+}
+
+func (c *Cache) SaveSections(slug string, sections []Section) error {
+	data, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.sectionsPath(slug), data, 0644)
+}
+
+// pageEntries maps the pages of a docset to the names of their entries, by
+// anchor. The page itself has the empty anchor.
+func pageEntries(entries []DocumentEntry) map[string]map[string]string {
+	pages := make(map[string]map[string]string)
+	for _, entry := range entries {
+		page, fragment := entry.SplitFragment()
+		anchor := strings.TrimPrefix(fragment, "#")
+		if pages[page] == nil {
+			pages[page] = make(map[string]string)
+		}
+		if _, ok := pages[page][anchor]; !ok {
+			pages[page][anchor] = entry.Name
+		}
+	}
+	return pages
+}
+
+// extractSections finds the headings and definition terms with an id in a
+// page that aren't entries of the index. Each belongs to the closest index
+// entry before it, or the entry of the page.
+func extractSections(page, content string, anchors map[string]string) ([]Section, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	parent := anchors[""]
+	if parent == "" {
+		// Pages without an entry of their own belong to their first entry
+		for _, name := range anchors {
+			if parent == "" || name < parent {
+				parent = name
+			}
+		}
+	}
+
+	var sections []Section
+	seen := make(map[string]bool)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id := attr(n, "id"); id != "" && !seen[id] {
+				seen[id] = true
+				if name, ok := anchors[id]; ok {
+					parent = name
+				} else if headingLevel(n) > 0 || n.DataAtom == atom.Dt {
+					if name := strings.TrimSpace(collapseSpace(textContent(n))); name != "" {
+						sections = append(sections, Section{
+							Name:   name,
+							Path:   page + "#" + id,
+							Parent: parent,
+						})
+					}
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return sections, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+// defaultSearchLimit caps the number of results returned by /api/search
+const defaultSearchLimit = 50
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>DevDocs</title>
+</head>
+<body>
+<h1>DevDocs</h1>
+<form id="search">
+<input type="search" name="q" placeholder="Search all documentation" autofocus>
+</form>
+<ul id="results"></ul>
+<h2>Installed documentation</h2>
+<ul>
+{{range .}}<li><a href="/{{.Slug}}/">{{.Slug}}</a>{{if .Meta.Release}} {{.Meta.Release}}{{end}}</li>
+{{else}}<li>No documentation installed. Use 'ddc download' first.</li>
+{{end}}</ul>
+<script>
+const form = document.getElementById("search");
+const results = document.getElementById("results");
+form.q.addEventListener("input", async () => {
+	const q = form.q.value.trim();
+	results.innerHTML = "";
+	if (!q) return;
+	const resp = await fetch("/api/search?q=" + encodeURIComponent(q));
+	for (const r of await resp.json()) {
+		const li = document.createElement("li");
+		const a = document.createElement("a");
+		a.href = r.url;
+		a.textContent = r.docset + ": " + r.name;
+		li.appendChild(a);
+		results.appendChild(li);
+	}
+});
+form.addEventListener("submit", (e) => e.preventDefault());
+</script>
+</body>
+</html>
+`))
+
+var docsetTemplate = template.Must(template.New("docset").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Slug}} - DevDocs</title>
+</head>
+<body>
+<p><a href="/">All documentation</a></p>
+<h1>{{.Slug}}{{if .Meta.Release}} {{.Meta.Release}}{{end}}</h1>
+<ul>
+{{range .Entries}}<li><a href="{{.URL}}">{{.Name}}</a> <small>{{.Type}}</small></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+type servedDocset struct {
+	Slug string
+	Meta DocMeta
+}
+
+type servedEntry struct {
+	Docset string `json:"docset"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Score  int    `json:"score"`
+}
+
+// Server serves installed documentation over HTTP
+type Server struct {
+	cache *Cache
+}
+
+func NewServer(cache *Cache) *Server {
+	return &Server{cache: cache}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleLanding)
+	mux.HandleFunc("GET /api/search", s.handleSearch)
+	mux.HandleFunc("GET /{slug}/{$}", s.handleDocset)
+	mux.HandleFunc("GET /{slug}/html/", s.handleFiles)
+	mux.HandleFunc("GET /{slug}/assets/", s.handleFiles)
+	return mux
+}
+
+// entryURL returns the URL an entry of a docset is served at
+func (s *Server) entryURL(slug string, path string) string {
+	htmlPath, fragment := s.cache.GetHTMLPath(slug, path)
+	rel, err := filepath.Rel(s.cache.BaseDir, htmlPath)
+	if err != nil {
+		return "/" + slug + "/"
+	}
+	return "/" + filepath.ToSlash(rel) + fragment
+}
+
+func (s *Server) handleLanding(w http.ResponseWriter, r *http.Request) {
+	slugs, err := installedDocsets(s.cache)
+	if err != nil {
+		slugs = nil
+	}
+
+	docsets := make([]servedDocset, 0, len(slugs))
+	for _, slug := range slugs {
+		meta, _ := s.cache.GetMeta(slug)
+		docsets = append(docsets, servedDocset{Slug: slug, Meta: meta})
+	}
+
+	if err := landingTemplate.Execute(w, docsets); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *Server) handleDocset(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if !s.cache.DocsetExists(slug) {
+		http.NotFound(w, r)
+		return
+	}
+
+	entries, err := loadEntries(s.cache, slug)
+	if err != nil {
+		http.Error(w, "failed to read index.json", http.StatusInternalServerError)
+		return
+	}
+
+	// The page lists the entries of the index, not the sections of pages
+	served := make([]servedEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type == sectionType {
+			continue
+		}
+		served = append(served, servedEntry{
+			Docset: slug,
+			Name:   entry.Name,
+			Path:   entry.Path,
+			Type:   entry.Type,
+			URL:    s.entryURL(slug, entry.Path),
+		})
+	}
+
+	meta, _ := s.cache.GetMeta(slug)
+	data := struct {
+		Slug    string
+		Meta    DocMeta
+		Entries []servedEntry
+	}{slug, meta, served}
+
+	if err := docsetTemplate.Execute(w, data); err != nil {
+		log.Println(err)
+	}
+}
+
+// handleFiles serves unpacked pages and downloaded assets. Other files of
+// a docset, such as db.json, are not exposed.
+func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	if !s.cache.DocsetExists(r.PathValue("slug")) {
+		http.NotFound(w, r)
+		return
+	}
+	http.FileServer(http.Dir(s.cache.BaseDir)).ServeHTTP(w, r)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "missing q parameter", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	results, err := searchDocsets(s.cache, query, limit, r.URL.Query()["docset"]...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	served := make([]servedEntry, len(results))
+	for i, result := range results {
+		served[i] = servedEntry{
+			Docset: result.docset,
+			Name:   result.entry.Name,
+			Path:   result.entry.Path,
+			Type:   result.entry.Type,
+			URL:    s.entryURL(result.docset, result.entry.Path),
+			Score:  result.score,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(served); err != nil {
+		log.Println(err)
+	}
+}
@@ -13,4 +13,7 @@ var (
 	helpStyle         = list.DefaultStyles(false).HelpStyle.PaddingLeft(4).PaddingBottom(1)
 	focusedStyle      = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("39"))
+	matchedStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	dimmedStyle  = lipgloss.NewStyle().Faint(true)
+	headerStyle  = lipgloss.NewStyle().PaddingLeft(2).Bold(true)
 )
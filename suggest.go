@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// editDistance is the optimal string alignment distance between a and b:
+// the insertions, deletions, substitutions and swaps of adjacent characters
+// turning one into the other
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+// isTypo reports whether name looks like a misspelling of candidate
+func isTypo(name, candidate string) bool {
+	maxDistance := 1
+	if len(name) > 4 {
+		maxDistance = 2
+	}
+	d := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+	return d > 0 && d <= maxDistance
+}
+
+// closestInstalled returns the installed docset whose directory or devdocs
+// slug is closest to a misspelled name
+func closestInstalled(cache *Cache, name string) (string, bool) {
+	installed := cache.InstalledSlugs()
+	slugs := make([]string, 0, len(installed))
+	for slug := range installed {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	best, bestDistance := "", -1
+	for _, slug := range slugs {
+		if !isTypo(name, slug) {
+			continue
+		}
+		if d := editDistance(strings.ToLower(name), strings.ToLower(slug)); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = installed[slug], d
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// catalogSuggestion looks a name up in the cached catalog: a docset family
+// by name or slug, a specific version by its slug, else, with typos, the
+// family closest to a misspelled name
+func catalogSuggestion(cache *Cache, name string, typos bool) (Documentation, bool) {
+	catalog, err := CachedDocumentations(cache)
+	if err != nil {
+		return Documentation{}, false
+	}
+
+	lower := strings.ToLower(name)
+	for _, doc := range catalog {
+		for _, version := range doc.ListVersions() {
+			if version.Slug == lower {
+				return version, true
+			}
+		}
+		base, _, _ := strings.Cut(doc.Slug, "~")
+		if doc.Kind() == lower || base == lower {
+			return doc.GetLatestVersion(), true
+		}
+	}
+	if !typos {
+		return Documentation{}, false
+	}
+
+	var best Documentation
+	bestDistance := -1
+	for _, doc := range catalog {
+		base, _, _ := strings.Cut(doc.Slug, "~")
+		for _, candidate := range []string{doc.Kind(), base} {
+			if !isTypo(name, candidate) {
+				continue
+			}
+			if d := editDistance(lower, candidate); bestDistance < 0 || d < bestDistance {
+				best, bestDistance = doc.GetLatestVersion(), d
+			}
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// resolveDocset returns the directory of the installed docset named by its
+// directory or devdocs slug. For a name that isn't installed, it offers to
+// install the docset from the catalog and, with typos, first asks whether a
+// close installed docset was meant. It reports false when nothing was
+// picked.
+func resolveDocset(cache *Cache, client *DevDoc, name string, typos bool) (string, bool, error) {
+	if dir, ok := cache.InstalledSlugs()[strings.ToLower(name)]; ok {
+		return dir, true, nil
+	}
+
+	if slug, ok := closestInstalled(cache, name); typos && ok {
+		if confirm(fmt.Sprintf("Documentation %s is not installed. Did you mean %s?", name, slug)) {
+			return slug, true, nil
+		}
+	}
+
+	doc, ok := catalogSuggestion(cache, name, typos)
+	if !ok {
+		return "", false, nil
+	}
+
+	question := fmt.Sprintf("%s is available but not installed, install now?", doc.GetDisplayName())
+	if base, _, _ := strings.Cut(doc.Slug, "~"); doc.Kind() != strings.ToLower(name) && base != strings.ToLower(name) && doc.Slug != strings.ToLower(name) {
+		question = fmt.Sprintf("Did you mean %s? It is available but not installed, install now?", doc.GetDisplayName())
+	}
+	if !confirm(question) {
+		return "", false, nil
+	}
+
+	dir := versionDir(cache, doc)
+	fmt.Printf("Downloading %s...\n", doc.GetDisplayName())
+	if err := client.DownloadDocSetTo(&doc, dir); err != nil {
+		return "", false, err
+	}
+	return dir, true, nil
+}
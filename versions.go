@@ -0,0 +1,150 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// versionDir picks where to install a version of a docset family: the
+// family directory, unless it holds another version, which then stays
+// installed next to the new one in a directory named by its devdocs slug
+func versionDir(cache *Cache, doc Documentation) string {
+	meta, err := cache.GetMeta(doc.Kind())
+	if err != nil || meta.Slug == "" || meta.Slug == doc.Slug {
+		return doc.Kind()
+	}
+	return doc.Slug
+}
+
+// installedVersion is an installed docset as a version of its family
+type installedVersion struct {
+	dir   string
+	label string // Release or version shown for it
+}
+
+// docsetFamily returns the family of an installed docset, its devdocs slug
+// without the version
+func docsetFamily(cache *Cache, dir string) string {
+	slug := dir
+	if meta, err := cache.GetMeta(dir); err == nil && meta.Slug != "" {
+		slug = meta.Slug
+	}
+	family, _, _ := strings.Cut(slug, "~")
+	return family
+}
+
+// installedFamilies groups installed docsets by family, newest version first
+func installedFamilies(cache *Cache) (map[string][]installedVersion, error) {
+	slugs, err := installedDocsets(cache)
+	if err != nil {
+		return nil, err
+	}
+
+	families := make(map[string][]installedVersion)
+	for _, dir := range slugs {
+		v := installedVersion{dir: dir, label: dir}
+		if meta, err := cache.GetMeta(dir); err == nil {
+			if meta.Release != "" {
+				v.label = meta.Release
+			} else if meta.Version != "" {
+				v.label = meta.Version
+			}
+		}
+		family := docsetFamily(cache, dir)
+		families[family] = append(families[family], v)
+	}
+
+	for _, versions := range families {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return CompareVersions(versions[i].label, versions[j].label) > 0
+		})
+	}
+	return families, nil
+}
+
+// searchVersions searches every installed version of the families of the
+// given docsets, or of all installed docsets, and collapses the results into
+// one per entry name and family. Results of families with more than one
+// version installed list the versions they are in and missing from.
+func searchVersions(cache *Cache, query string, limit int, docsets ...string) ([]searchResult, error) {
+	families, err := installedFamilies(cache)
+	if err != nil {
+		return nil, err
+	}
+
+	familyOf := make(map[string]string)
+	largest := 1
+	for family, versions := range families {
+		for _, v := range versions {
+			familyOf[v.dir] = family
+		}
+		largest = max(largest, len(versions))
+	}
+
+	// Search all versions of the given docsets, keeping their order
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, slug := range docsets {
+		family, ok := familyOf[slug]
+		if !ok {
+			dirs = append(dirs, slug) // Not installed, let searchDocsets say so
+			continue
+		}
+		if seen[family] {
+			continue
+		}
+		seen[family] = true
+		for _, v := range families[family] {
+			dirs = append(dirs, v.dir)
+		}
+	}
+
+	// Every version of an entry may rank before the next entry
+	found, err := searchDocsets(cache, query, limit*largest, dirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	type entryKey struct{ family, name string }
+	index := make(map[entryKey]int)
+	var results []searchResult
+	for _, result := range found {
+		key := entryKey{familyOf[result.docset], result.entry.Name}
+		if _, ok := index[key]; !ok {
+			index[key] = len(results)
+			results = append(results, result)
+		}
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	// Whether a version has an entry is decided by its index, as it may
+	// have ranked below the results searched
+	names := make(map[string]map[string]bool)
+	hasEntry := func(dir, name string) bool {
+		if _, ok := names[dir]; !ok {
+			names[dir] = make(map[string]bool)
+			entries, _ := loadEntries(cache, dir)
+			for _, entry := range entries {
+				names[dir][entry.Name] = true
+			}
+		}
+		return names[dir][name]
+	}
+
+	for i := range results {
+		versions := families[familyOf[results[i].docset]]
+		if len(versions) < 2 {
+			continue
+		}
+		for _, v := range versions {
+			if hasEntry(v.dir, results[i].entry.Name) {
+				results[i].versions = append(results[i].versions, v.label)
+			} else {
+				results[i].missing = append(results[i].missing, v.label)
+			}
+		}
+	}
+	return results, nil
+}
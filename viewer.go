@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 
+	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/list"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
@@ -53,6 +54,7 @@ type EntryModel struct {
 	width    int
 	height   int
 	err      error
+	status   string
 	cache    *Cache
 	slug     string
 }
@@ -70,6 +72,7 @@ func NewEntryModel(entries []DocumentEntry, cache *Cache, slug string) EntryMode
 	l.Styles.Title = titleStyle
 	l.Styles.PaginationStyle = paginationStyle
 	l.Styles.HelpStyle = helpStyle
+	useKeys(&l, bookmarkKey)
 
 	return EntryModel{
 		list:  l,
@@ -99,26 +102,17 @@ func (m EntryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				break
 			}
 			selected := m.GetSelected()
-			
-			// Get the file path and fragment
-			htmlPath, fragment := m.cache.GetHTMLPath(m.slug, selected.Path)
-			
-			// Pass the path with fragment to lynx
-			// args := []string{htmlPath}
-			// if fragment != "" {
-			// 	// For lynx, we can use -jumpfile to jump to a specific fragment
-			// 	args = append(args, "-jumpfile", fragment[1:]) // Remove the # from fragment
-			// }
-			
-			cmd := exec.Command("lynx", htmlPath + "#" + fragment)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				m.err = fmt.Errorf("failed to open documentation: %w, trying %s", err, htmlPath)
+			if err := openEntry(m.cache, m.slug, selected); err != nil {
+				m.err = err
 				return m, nil
 			}
 			return m, tea.Quit
+		case "b":
+			if m.list.SettingFilter() {
+				break
+			}
+			m.status, m.err = toggleBookmark(m.cache, m.slug, m.GetSelected())
+			return m, nil
 		}
 	}
 
@@ -128,6 +122,9 @@ func (m EntryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m EntryModel) View() string {
 	view := "\n" + m.list.View()
+	if m.status != "" {
+		view += "\n\n" + m.status
+	}
 	if m.err != nil {
 		view += "\n\nError: " + m.err.Error()
 	}
@@ -140,3 +137,43 @@ func (m EntryModel) GetSelected() DocumentEntry {
 	}
 	return DocumentEntry{}
 }
+
+// openEntry opens an entry of an installed docset in lynx and records the
+// visit in the history
+func openEntry(cache *Cache, slug string, entry DocumentEntry) error {
+	htmlPath, fragment := cache.GetHTMLPath(slug, entry.Path)
+
+	cmd := exec.Command("lynx", htmlPath+fragment)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open documentation: %w, trying %s", err, htmlPath)
+	}
+	return cache.RecordVisit(slug, entry)
+}
+
+// bookmarkKey bookmarks the selected entry of a list
+var bookmarkKey = key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "bookmark"))
+
+// useKeys takes keys of a list for a model's own bindings and shows them
+// in the help of the list. b is taken from the previous page keys, which
+// keep left, h, pgup and u.
+func useKeys(l *list.Model, bindings ...key.Binding) {
+	l.KeyMap.PrevPage.SetKeys("left", "h", "pgup", "u")
+	help := func() []key.Binding { return bindings }
+	l.AdditionalShortHelpKeys = help
+	l.AdditionalFullHelpKeys = help
+}
+
+// toggleBookmark bookmarks or unbookmarks an entry and returns a status line
+func toggleBookmark(cache *Cache, slug string, entry DocumentEntry) (string, error) {
+	bookmarked, err := cache.ToggleBookmark(slug, entry)
+	if err != nil {
+		return "", err
+	}
+	if bookmarked {
+		return fmt.Sprintf("Bookmarked %s", entry.Name), nil
+	}
+	return fmt.Sprintf("Removed bookmark %s", entry.Name), nil
+}